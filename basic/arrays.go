@@ -0,0 +1,174 @@
+/**************************************************************/
+/*
+   arrays.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+// defaultBound is the upper bound of an array used without DIM.
+const defaultBound = 10
+
+// array is the storage of a DIM array. Subscripts run from 0 to the
+// bound of each dimension; elements are kept in row-major order.
+type array struct {
+	isStr bool
+	typ   NumType
+	dims  []int // upper bounds
+	vals  []Value
+}
+
+func newArray(isStr bool, typ NumType, dims []int) *array {
+	n := 1
+	for _, d := range dims {
+		n *= d + 1
+	}
+	a := &array{isStr: isStr, typ: typ, dims: dims, vals: make([]Value, n)}
+	for i := range a.vals {
+		if isStr {
+			a.vals[i] = StringValue("")
+		} else {
+			a.vals[i] = Value{Kind: ValNumber, Type: typ}
+		}
+	}
+	return a
+}
+
+// index returns the position of the element at subs.
+func (a *array) index(name string, subs []Value) (int, error) {
+	if len(subs) != len(a.dims) {
+		return 0, newError(ErrSubscriptRange, "%s has %d dimensions", name, len(a.dims))
+	}
+	i := 0
+	for k, v := range subs {
+		if v.Kind != ValNumber {
+			return 0, newError(ErrTypeMismatch, "subscript of %s must be numeric", name)
+		}
+		n, err := toType(v, Integer)
+		if err != nil || n.Num < 0 || int(n.Num) > a.dims[k] {
+			return 0, newError(ErrSubscriptRange, "subscript out of range: %s", name)
+		}
+		i = i*(a.dims[k]+1) + int(n.Num)
+	}
+	return i, nil
+}
+
+// dim creates the array name with the bounds dims.
+func (e *Env) dim(name string, dims []int) error {
+	if _, ok := e.arrays[name]; ok {
+		return newError(ErrDuplicateDefinition, "%s is already dimensioned", name)
+	}
+	e.setArray(name, dims)
+	return nil
+}
+
+// setArray creates or replaces the array name.
+func (e *Env) setArray(name string, dims []int) *array {
+	isStr, typ := e.syms.typeOf(name)
+	a := newArray(isStr, typ, dims)
+	e.putArray(name, a)
+	return a
+}
+
+// putArray makes a the array name.
+func (e *Env) putArray(name string, a *array) {
+	if e.arrays == nil {
+		e.arrays = map[string]*array{}
+	}
+	e.arrays[name] = a
+}
+
+// array returns the array name, dimensioning it with n subscripts of
+// defaultBound on first assignment as classic BASIC does.
+func (e *Env) array(name string, n int) *array {
+	if a, ok := e.arrays[name]; ok {
+		return a
+	}
+	dims := make([]int, n)
+	for i := range dims {
+		dims[i] = defaultBound
+	}
+	return e.setArray(name, dims)
+}
+
+// element returns name(subs...). Reading an array that was neither
+// dimensioned nor assigned is a call of an undefined function.
+func (it *Interpreter) element(name string, subs []Value) (Value, error) {
+	a, ok := it.Env.arrays[name]
+	if !ok {
+		return Value{}, newError(ErrUndefinedFunction, "undefined function %s", name)
+	}
+	i, err := a.index(name, subs)
+	if err != nil {
+		return Value{}, err
+	}
+	return a.vals[i], nil
+}
+
+// setElement assigns v to name(subs...).
+func (it *Interpreter) setElement(name string, subs []Value, v Value) error {
+	a := it.Env.array(name, len(subs))
+	i, err := a.index(name, subs)
+	if err != nil {
+		return err
+	}
+	return it.storeElement(name, a, i, v)
+}
+
+func (it *Interpreter) storeElement(name string, a *array, i int, v Value) error {
+	if err := it.checkStore(v, false, 0); err != nil {
+		return err
+	}
+	if err := checkKind(name, a.isStr, v); err != nil {
+		return err
+	}
+	if v.Kind == ValNumber && v.Type != a.typ {
+		var err error
+		if v, err = toType(v, a.typ); err != nil {
+			return err
+		}
+	}
+	v.lit = false
+	a.vals[i] = v
+	return nil
+}
+
+// letElement runs LET for an array element.
+func (it *Interpreter) letElement(s *LetStmt) error {
+	subs, err := it.evalArgs(s.Index)
+	if err != nil {
+		return err
+	}
+	v, err := it.evalExpr(s.Expr)
+	if err != nil {
+		return err
+	}
+	return it.setElement(s.Name, subs, v)
+}
+
+// dimStmt runs DIM.
+func (it *Interpreter) dimStmt(s *DimStmt) error {
+	for i, name := range s.Names {
+		bounds, err := it.evalArgs(s.Bounds[i])
+		if err != nil {
+			return err
+		}
+		dims := make([]int, len(bounds))
+		for k, b := range bounds {
+			if b.Kind != ValNumber {
+				return newError(ErrTypeMismatch, "DIM bound must be numeric")
+			}
+			n, err := toType(b, Integer)
+			if err != nil || n.Num < 0 {
+				return newError(ErrIllegalFunction, "bad DIM bound %s", b)
+			}
+			dims[k] = int(n.Num)
+		}
+		if err := it.Env.dim(name, dims); err != nil {
+			return err
+		}
+	}
+	return nil
+}
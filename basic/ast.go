@@ -0,0 +1,513 @@
+/**************************************************************/
+/*
+   ast.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pos is the position of a node: the column, from 1, of its first
+// character in the source of its line, or 0 when unknown.
+type Pos struct {
+	Col int
+}
+
+func (p *Pos) pos() *Pos { return p }
+
+// positioned is implemented by the nodes, which embed Pos.
+type positioned interface {
+	pos() *Pos
+}
+
+func setCol(n any, col int) {
+	if p, ok := n.(positioned); ok {
+		p.pos().Col = col
+	}
+}
+
+type Stmt interface {
+	stmtNode()
+	String() string
+}
+
+type Expr interface {
+	exprNode()
+	String() string
+}
+
+// statements
+
+type RemStmt struct {
+	Pos
+}
+
+func (s *RemStmt) stmtNode()      {}
+func (s *RemStmt) String() string { return "REM" }
+
+type LetStmt struct {
+	Pos
+	Name  string
+	Slot  int    `json:"-"` // variable slot, resolved by Program.Link
+	Index []Expr // subscripts of an array element
+	Expr  Expr
+}
+
+func (s *LetStmt) stmtNode() {}
+func (s *LetStmt) String() string {
+	if s.Index != nil {
+		return fmt.Sprintf("%s(%s) = %s", s.Name, joinExprs(s.Index), s.Expr)
+	}
+	return fmt.Sprintf("%s = %s", s.Name, s.Expr.String())
+}
+
+type PrintStmt struct {
+	Pos
+	File  Expr   // file number of PRINT #, nil for the screen
+	Using Expr   // format of PRINT USING, nil for PRINT
+	Exprs []Expr // empty => PRINT only (blank line)
+}
+
+func (s *PrintStmt) stmtNode() {}
+func (s *PrintStmt) String() string {
+	head := "PRINT"
+	if s.File != nil {
+		head += " #" + s.File.String() + ","
+	}
+	parts := make([]string, 0, len(s.Exprs))
+	for _, e := range s.Exprs {
+		parts = append(parts, e.String())
+	}
+	if s.Using != nil {
+		return head + " USING " + s.Using.String() + "; " + strings.Join(parts, ", ")
+	}
+	if len(s.Exprs) == 0 {
+		return strings.TrimSuffix(head, ",")
+	}
+	return head + " " + strings.Join(parts, ", ")
+}
+
+type InputStmt struct {
+	Pos
+	Name string
+	Slot int `json:"-"`
+}
+
+func (s *InputStmt) stmtNode()      {}
+func (s *InputStmt) String() string { return "INPUT " + s.Name }
+
+type IfStmt struct {
+	Pos
+	Cond     Expr
+	ThenStmt Stmt // either ThenStmt or ThenLine is used
+	ThenLine int
+	HasLine  bool
+}
+
+func (s *IfStmt) stmtNode() {}
+func (s *IfStmt) String() string {
+	if s.HasLine {
+		return fmt.Sprintf("IF %s THEN %d", s.Cond.String(), s.ThenLine)
+	}
+	return fmt.Sprintf("IF %s THEN %s", s.Cond.String(), s.ThenStmt.String())
+}
+
+type GotoStmt struct {
+	Pos
+	Line int `json:"target"`
+}
+
+func (s *GotoStmt) stmtNode()      {}
+func (s *GotoStmt) String() string { return fmt.Sprintf("GOTO %d", s.Line) }
+
+type GosubStmt struct {
+	Pos
+	Line int `json:"target"`
+}
+
+func (s *GosubStmt) stmtNode()      {}
+func (s *GosubStmt) String() string { return fmt.Sprintf("GOSUB %d", s.Line) }
+
+type ReturnStmt struct {
+	Pos
+}
+
+func (s *ReturnStmt) stmtNode()      {}
+func (s *ReturnStmt) String() string { return "RETURN" }
+
+// OnErrorStmt is ON ERROR GOTO line; line 0 turns error trapping off.
+type OnErrorStmt struct {
+	Pos
+	Line int `json:"target"`
+}
+
+func (s *OnErrorStmt) stmtNode()      {}
+func (s *OnErrorStmt) String() string { return fmt.Sprintf("ON ERROR GOTO %d", s.Line) }
+
+// ResumeStmt is RESUME, RESUME NEXT or RESUME line.
+type ResumeStmt struct {
+	Pos
+	Next bool
+	Line int `json:"target"` // 0: resume at the failed statement
+}
+
+func (s *ResumeStmt) stmtNode() {}
+func (s *ResumeStmt) String() string {
+	switch {
+	case s.Next:
+		return "RESUME NEXT"
+	case s.Line != 0:
+		return fmt.Sprintf("RESUME %d", s.Line)
+	default:
+		return "RESUME"
+	}
+}
+
+type ErrorStmt struct {
+	Pos
+	Code Expr
+}
+
+func (s *ErrorStmt) stmtNode()      {}
+func (s *ErrorStmt) String() string { return "ERROR " + s.Code.String() }
+
+// RandomizeStmt reseeds RND. RANDOMIZE without a seed is RANDOMIZE TIMER.
+type RandomizeStmt struct {
+	Pos
+	Seed Expr
+}
+
+func (s *RandomizeStmt) stmtNode()      {}
+func (s *RandomizeStmt) String() string { return "RANDOMIZE " + s.Seed.String() }
+
+// DefStmt is DEFINT, DEFSNG, DEFDBL or DEFSTR. It declares the type of
+// variables without suffix whose name starts with one of the letters; it
+// applies to the whole program wherever it appears.
+type DefStmt struct {
+	Pos
+	Str    bool    // DEFSTR
+	Type   NumType // numeric type otherwise
+	Ranges []LetterRange
+}
+
+type LetterRange struct {
+	From, To byte
+}
+
+func (s *DefStmt) stmtNode() {}
+func (s *DefStmt) String() string {
+	kw := map[NumType]string{Integer: "DEFINT", Single: "DEFSNG", Double: "DEFDBL"}[s.Type]
+	if s.Str {
+		kw = "DEFSTR"
+	}
+	parts := make([]string, 0, len(s.Ranges))
+	for _, r := range s.Ranges {
+		if r.From == r.To {
+			parts = append(parts, string(r.From))
+		} else {
+			parts = append(parts, string(r.From)+"-"+string(r.To))
+		}
+	}
+	return kw + " " + strings.Join(parts, ", ")
+}
+
+// OptionStmt is OPTION DECIMAL, which turns decimal mode on.
+type OptionStmt struct {
+	Pos
+	Name string
+}
+
+func (s *OptionStmt) stmtNode()      {}
+func (s *OptionStmt) String() string { return "OPTION " + s.Name }
+
+// OpenStmt is OPEN name FOR mode AS #file [LEN=reclen].
+type OpenStmt struct {
+	Pos
+	Name Expr
+	Mode fileMode
+	File Expr
+	Len  Expr // record length of RANDOM, nil for the default
+}
+
+func (s *OpenStmt) stmtNode() {}
+func (s *OpenStmt) String() string {
+	str := fmt.Sprintf("OPEN %s FOR %s AS #%s", s.Name, s.Mode, s.File)
+	if s.Len != nil {
+		str += " LEN = " + s.Len.String()
+	}
+	return str
+}
+
+// CloseStmt is CLOSE #file, ...; without files it closes all of them.
+type CloseStmt struct {
+	Pos
+	Files []Expr
+}
+
+func (s *CloseStmt) stmtNode() {}
+func (s *CloseStmt) String() string {
+	parts := make([]string, 0, len(s.Files))
+	for _, f := range s.Files {
+		parts = append(parts, "#"+f.String())
+	}
+	return strings.TrimSpace("CLOSE " + strings.Join(parts, ", "))
+}
+
+// FileInputStmt is INPUT #file, var, ... or LINE INPUT #file, var$.
+type FileInputStmt struct {
+	Pos
+	File  Expr
+	Line  bool `json:"lineInput"`
+	Names []string
+	Slots []int `json:"-"`
+}
+
+func (s *FileInputStmt) stmtNode() {}
+func (s *FileInputStmt) String() string {
+	head := "INPUT"
+	if s.Line {
+		head = "LINE INPUT"
+	}
+	return fmt.Sprintf("%s #%s, %s", head, s.File, strings.Join(s.Names, ", "))
+}
+
+// FieldStmt is FIELD #file, width AS var$, ...
+type FieldStmt struct {
+	Pos
+	File   Expr
+	Widths []Expr
+	Names  []string
+	Slots  []int `json:"-"`
+}
+
+func (s *FieldStmt) stmtNode() {}
+func (s *FieldStmt) String() string {
+	parts := make([]string, 0, len(s.Names))
+	for i, name := range s.Names {
+		parts = append(parts, fmt.Sprintf("%s AS %s", s.Widths[i], name))
+	}
+	return fmt.Sprintf("FIELD #%s, %s", s.File, strings.Join(parts, ", "))
+}
+
+// RecordStmt is GET #file[, record] or PUT #file[, record].
+type RecordStmt struct {
+	Pos
+	Put    bool
+	File   Expr
+	Record Expr // nil for the record after the last one
+}
+
+func (s *RecordStmt) stmtNode() {}
+func (s *RecordStmt) String() string {
+	str := "GET #" + s.File.String()
+	if s.Put {
+		str = "PUT #" + s.File.String()
+	}
+	if s.Record != nil {
+		str += ", " + s.Record.String()
+	}
+	return str
+}
+
+// LsetStmt is LSET var$ = expr, or RSET when Right is set.
+type LsetStmt struct {
+	Pos
+	Right bool
+	Name  string
+	Slot  int `json:"-"`
+	Expr  Expr
+}
+
+func (s *LsetStmt) stmtNode() {}
+func (s *LsetStmt) String() string {
+	return fmt.Sprintf("%s %s = %s", s.keyword(), s.Name, s.Expr)
+}
+
+func (s *LsetStmt) keyword() string {
+	if s.Right {
+		return "RSET"
+	}
+	return "LSET"
+}
+
+// DimStmt is DIM name(bounds), ...
+type DimStmt struct {
+	Pos
+	Names  []string
+	Bounds [][]Expr
+}
+
+func (s *DimStmt) stmtNode() {}
+func (s *DimStmt) String() string {
+	parts := make([]string, 0, len(s.Names))
+	for i, name := range s.Names {
+		parts = append(parts, fmt.Sprintf("%s(%s)", name, joinExprs(s.Bounds[i])))
+	}
+	return "DIM " + strings.Join(parts, ", ")
+}
+
+// CSVStmt is CSVREAD file INTO arrays [HEADER] [COUNT var], or
+// CSVWRITE file FROM arrays [HEADER] when Write is set.
+type CSVStmt struct {
+	Pos
+	Write  bool
+	Name   Expr
+	Arrays []string
+	Header bool
+	Count  string // variable set to the number of rows read, or ""
+	Slot   int    `json:"-"`
+}
+
+func (s *CSVStmt) stmtNode() {}
+func (s *CSVStmt) String() string {
+	dir := "INTO"
+	if s.Write {
+		dir = "FROM"
+	}
+	str := fmt.Sprintf("%s %s %s %s()", s.keyword(), s.Name, dir, strings.Join(s.Arrays, "(), "))
+	if s.Header {
+		str += " HEADER"
+	}
+	if s.Count != "" {
+		str += " COUNT " + s.Count
+	}
+	return str
+}
+
+func (s *CSVStmt) keyword() string {
+	if s.Write {
+		return "CSVWRITE"
+	}
+	return "CSVREAD"
+}
+
+// SnapshotStmt is SNAPSHOT file, or RESUME SNAPSHOT file when Resume is
+// set.
+type SnapshotStmt struct {
+	Pos
+	Resume bool
+	Name   Expr
+}
+
+func (s *SnapshotStmt) stmtNode()      {}
+func (s *SnapshotStmt) String() string { return s.keyword() + " " + s.Name.String() }
+
+func (s *SnapshotStmt) keyword() string {
+	if s.Resume {
+		return "RESUME SNAPSHOT"
+	}
+	return "SNAPSHOT"
+}
+
+type EndStmt struct {
+	Pos
+}
+
+func (s *EndStmt) stmtNode()      {}
+func (s *EndStmt) String() string { return "END" }
+
+// ExtStmt is a statement registered with RegisterStatement.
+type ExtStmt struct {
+	Pos
+	Keyword string
+	Args    []Expr
+	Data    any // set by the parse function
+
+	ext *stmtExt
+}
+
+func (s *ExtStmt) stmtNode() {}
+func (s *ExtStmt) String() string {
+	if len(s.Args) == 0 {
+		return s.Keyword
+	}
+	parts := make([]string, 0, len(s.Args))
+	for _, a := range s.Args {
+		parts = append(parts, a.String())
+	}
+	return s.Keyword + " " + strings.Join(parts, ", ")
+}
+
+// expressions
+
+type NumberLit struct {
+	Pos
+	Value float64
+}
+
+func (e *NumberLit) exprNode() {}
+func (e *NumberLit) String() string {
+	return strconv.FormatFloat(e.Value, 'g', -1, 64)
+}
+
+type StringLit struct {
+	Pos
+	Value string
+}
+
+func (e *StringLit) exprNode()      {}
+func (e *StringLit) String() string { return strconv.Quote(e.Value) }
+
+type VarRef struct {
+	Pos
+	Name  string
+	Slot  int     `json:"-"`
+	IsStr bool    `json:"-"` // resolved with Slot
+	Type  NumType `json:"-"` // resolved with Slot
+}
+
+func (e *VarRef) exprNode()      {}
+func (e *VarRef) String() string { return e.Name }
+
+type UnaryExpr struct {
+	Pos
+	Op  string
+	Rhs Expr
+}
+
+func (e *UnaryExpr) exprNode()      {}
+func (e *UnaryExpr) String() string { return "(" + e.Op + e.Rhs.String() + ")" }
+
+type BinaryExpr struct {
+	Pos
+	Op  string
+	Lhs Expr
+	Rhs Expr
+}
+
+func (e *BinaryExpr) exprNode() {}
+func (e *BinaryExpr) String() string {
+	return "(" + e.Lhs.String() + " " + e.Op + " " + e.Rhs.String() + ")"
+}
+
+// FuncExpr calls a built-in or host function. Built-in functions without
+// arguments (ERR, ERL) are written without parentheses.
+type FuncExpr struct {
+	Pos
+	Name string
+	Args []Expr
+}
+
+func (e *FuncExpr) exprNode() {}
+func (e *FuncExpr) String() string {
+	if _, ok := builtinFuncs[e.Name]; ok && len(e.Args) == 0 {
+		return e.Name
+	}
+	return e.Name + "(" + joinExprs(e.Args) + ")"
+}
+
+// joinExprs lists exprs separated by commas.
+func joinExprs(exprs []Expr) string {
+	parts := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		parts = append(parts, e.String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,443 @@
+/**************************************************************/
+/*
+   astjson.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ASTVersion is the version of the JSON written by WriteAST. ReadAST
+// rejects other versions.
+const ASTVersion = 1
+
+// WriteAST writes the parsed program as JSON:
+//
+//	{
+//	  "version": 1,
+//	  "lines": [
+//	    {
+//	      "line": 10,
+//	      "src": "PRINT A + 1",
+//	      "stmt": {
+//	        "kind": "PrintStmt", "line": 10, "col": 1,
+//	        "exprs": [
+//	          {
+//	            "kind": "BinaryExpr", "line": 10, "col": 7, "op": "+",
+//	            "lhs": {"kind": "VarRef", "line": 10, "col": 7, "name": "A"},
+//	            "rhs": {"kind": "NumberLit", "line": 10, "col": 11, "value": 1}
+//	          }
+//	        ]
+//	      }
+//	    }
+//	  ]
+//	}
+//
+// Every node has its kind, the name of its type in this package (LetStmt,
+// FuncExpr, ...), the line number and the column of its first character
+// in src, as in Pos. The other members are the exported fields of the
+// type with the first letter lowered: nodes for Expr and Stmt fields,
+// arrays of nodes for []Expr, "DOUBLE", "SINGLE" or "INTEGER" for a
+// NumType, the mode name for OpenStmt.mode, {"from": "A", "to": "C"} for
+// a letter range and JSON values otherwise. The line a statement jumps to
+// (GOTO, GOSUB, ON ERROR GOTO, RESUME) is "target" and the LINE of LINE
+// INPUT # is "lineInput", so that "line" is always the position. Nil
+// nodes and empty lists are left out, and so are the variable slots
+// resolved by Link.
+func WriteAST(w io.Writer, prog *Program) error {
+	lines := []jsonObject{}
+	for _, line := range prog.OrderedLines() {
+		stmt, err := encodeNode(prog.Stmts[line], line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		lines = append(lines, jsonObject{
+			{"line", line},
+			{"src", prog.Source[line]},
+			{"stmt", stmt},
+		})
+	}
+	b, err := json.MarshalIndent(jsonObject{{"version", ASTVersion}, {"lines", lines}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ReadAST builds a program from the JSON written by WriteAST. Lines are
+// listed as their statements print: src is written for readers only, so
+// that a listing, an error message or a profile always shows the
+// statement that runs. Extension statements with Data are rejected:
+// their parse functions built it and nothing can restore it.
+func ReadAST(r io.Reader) (*Program, error) {
+	var doc struct {
+		Version int `json:"version"`
+		Lines   []struct {
+			Line int              `json:"line"`
+			Stmt *json.RawMessage `json:"stmt"`
+		} `json:"lines"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ast: %w", err)
+	}
+	if doc.Version != ASTVersion {
+		return nil, fmt.Errorf("ast: unsupported version %d", doc.Version)
+	}
+	prog := NewProgram()
+	for _, l := range doc.Lines {
+		if l.Line <= 0 || l.Stmt == nil {
+			return nil, fmt.Errorf("ast: line %d: missing line number or statement", l.Line)
+		}
+		stmt, err := decodeStmt(*l.Stmt)
+		if err != nil {
+			return nil, fmt.Errorf("ast: line %d: %w", l.Line, err)
+		}
+		prog.SetLine(l.Line, stmt.String(), stmt)
+	}
+	return prog, nil
+}
+
+// astKinds maps the kinds of nodes to their types.
+var astKinds = kindsOf(
+	&RemStmt{}, &LetStmt{}, &PrintStmt{}, &InputStmt{}, &IfStmt{}, &GotoStmt{},
+	&GosubStmt{}, &ReturnStmt{}, &OnErrorStmt{}, &ResumeStmt{}, &ErrorStmt{},
+	&RandomizeStmt{}, &DefStmt{}, &OptionStmt{}, &OpenStmt{}, &CloseStmt{},
+	&FileInputStmt{}, &FieldStmt{}, &RecordStmt{}, &LsetStmt{}, &DimStmt{},
+	&CSVStmt{}, &SnapshotStmt{}, &EndStmt{}, &ExtStmt{},
+	&NumberLit{}, &StringLit{}, &VarRef{}, &UnaryExpr{}, &BinaryExpr{}, &FuncExpr{},
+)
+
+func kindsOf(nodes ...positioned) map[string]reflect.Type {
+	kinds := map[string]reflect.Type{}
+	for _, n := range nodes {
+		t := reflect.TypeOf(n).Elem()
+		kinds[t.Name()] = t
+	}
+	return kinds
+}
+
+// optionalNodes are the Expr and Stmt fields that may be nil.
+var optionalNodes = map[string]bool{
+	"PrintStmt.File":    true,
+	"PrintStmt.Using":   true,
+	"IfStmt.ThenStmt":   true,
+	"OpenStmt.Len":      true,
+	"RecordStmt.Record": true,
+}
+
+var (
+	exprType   = reflect.TypeFor[Expr]()
+	stmtType   = reflect.TypeFor[Stmt]()
+	exprsType  = reflect.TypeFor[[]Expr]()
+	exprs2Type = reflect.TypeFor[[][]Expr]()
+	rangesType = reflect.TypeFor[[]LetterRange]()
+	numType    = reflect.TypeFor[NumType]()
+	modeType   = reflect.TypeFor[fileMode]()
+)
+
+var numTypeNames = []string{Double: "DOUBLE", Single: "SINGLE", Integer: "INTEGER"}
+
+// jsonObject is a JSON object that keeps the order of its members.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key string
+	val any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(m.key)
+		v, err := json.Marshal(m.val)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// fieldKey returns the member name of the field f: its json tag or its
+// name with the first letter lowered.
+func fieldKey(f reflect.StructField) string {
+	if tag := f.Tag.Get("json"); tag != "" {
+		return tag
+	}
+	return strings.ToLower(f.Name[:1]) + f.Name[1:]
+}
+
+// encodeNode returns the JSON object of the node n on line.
+func encodeNode(n any, line int) (jsonObject, error) {
+	p, ok := n.(positioned)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %T", n)
+	}
+	v := reflect.ValueOf(n).Elem()
+	t := v.Type()
+	obj := jsonObject{{"kind", t.Name()}, {"line", line}, {"col", p.pos().Col}}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous || !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		val, ok, err := encodeField(v.Field(i), line)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			obj = append(obj, jsonMember{fieldKey(f), val})
+		}
+	}
+	return obj, nil
+}
+
+// encodeField returns the JSON value of a node field, or false to leave
+// it out.
+func encodeField(fv reflect.Value, line int) (any, bool, error) {
+	switch fv.Type() {
+	case exprType, stmtType:
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		obj, err := encodeNode(fv.Interface(), line)
+		return obj, true, err
+	case exprsType:
+		list, err := encodeExprs(fv.Interface().([]Expr), line)
+		return list, len(list) > 0, err
+	case exprs2Type:
+		var lists [][]jsonObject
+		for _, exprs := range fv.Interface().([][]Expr) {
+			list, err := encodeExprs(exprs, line)
+			if err != nil {
+				return nil, false, err
+			}
+			lists = append(lists, list)
+		}
+		return lists, len(lists) > 0, nil
+	case rangesType:
+		var list []jsonObject
+		for _, r := range fv.Interface().([]LetterRange) {
+			list = append(list, jsonObject{{"from", string(r.From)}, {"to", string(r.To)}})
+		}
+		return list, len(list) > 0, nil
+	case numType:
+		return numTypeNames[fv.Interface().(NumType)], true, nil
+	case modeType:
+		return fv.Interface().(fileMode).String(), true, nil
+	}
+	if fv.Kind() == reflect.Interface { // ExtStmt.Data
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		if _, err := json.Marshal(fv.Interface()); err != nil {
+			return nil, false, nil
+		}
+	}
+	return fv.Interface(), true, nil
+}
+
+func encodeExprs(exprs []Expr, line int) ([]jsonObject, error) {
+	var list []jsonObject
+	for _, e := range exprs {
+		obj, err := encodeNode(e, line)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, obj)
+	}
+	return list, nil
+}
+
+func decodeStmt(data []byte) (Stmt, error) {
+	n, err := decodeNode(data)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := n.(Stmt)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a statement", n)
+	}
+	return s, nil
+}
+
+func decodeExpr(data []byte) (Expr, error) {
+	n, err := decodeNode(data)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := n.(Expr)
+	if !ok {
+		return nil, fmt.Errorf("%T is not an expression", n)
+	}
+	return e, nil
+}
+
+// decodeNode rebuilds a node from its JSON object.
+func decodeNode(data []byte) (any, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	var kind string
+	json.Unmarshal(members["kind"], &kind)
+	t, ok := astKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown node kind %q", kind)
+	}
+	v := reflect.New(t)
+	if col, ok := members["col"]; ok {
+		if err := json.Unmarshal(col, &v.Interface().(positioned).pos().Col); err != nil {
+			return nil, fmt.Errorf("%s.col: %w", kind, err)
+		}
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous || !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		key := fieldKey(f)
+		data, ok := members[key]
+		if ok && string(data) != "null" {
+			if err := decodeField(v.Elem().Field(i), data); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", kind, key, err)
+			}
+			continue
+		}
+		if (f.Type == exprType || f.Type == stmtType) && !optionalNodes[kind+"."+f.Name] {
+			return nil, fmt.Errorf("%s: missing %s", kind, key)
+		}
+	}
+
+	switch n := v.Interface().(type) {
+	case *DimStmt:
+		if len(n.Names) != len(n.Bounds) {
+			return nil, fmt.Errorf("DimStmt: %d names but %d bounds", len(n.Names), len(n.Bounds))
+		}
+	case *FieldStmt:
+		if len(n.Names) != len(n.Widths) {
+			return nil, fmt.Errorf("FieldStmt: %d names but %d widths", len(n.Names), len(n.Widths))
+		}
+	case *IfStmt:
+		if !n.HasLine && n.ThenStmt == nil {
+			return nil, fmt.Errorf("IfStmt: missing thenStmt")
+		}
+	case *ExtStmt:
+		if n.ext, ok = lookupStmt(n.Keyword); !ok {
+			return nil, fmt.Errorf("unknown statement %s", n.Keyword)
+		}
+	}
+	return v.Interface(), nil
+}
+
+// decodeField sets the node field fv from its JSON value.
+func decodeField(fv reflect.Value, data []byte) error {
+	switch fv.Type() {
+	case exprType:
+		e, err := decodeExpr(data)
+		if err == nil {
+			fv.Set(reflect.ValueOf(&e).Elem())
+		}
+		return err
+	case stmtType:
+		s, err := decodeStmt(data)
+		if err == nil {
+			fv.Set(reflect.ValueOf(&s).Elem())
+		}
+		return err
+	case exprsType:
+		exprs, err := decodeExprs(data)
+		fv.Set(reflect.ValueOf(exprs))
+		return err
+	case exprs2Type:
+		var lists []json.RawMessage
+		if err := json.Unmarshal(data, &lists); err != nil {
+			return err
+		}
+		var all [][]Expr
+		for _, list := range lists {
+			exprs, err := decodeExprs(list)
+			if err != nil {
+				return err
+			}
+			all = append(all, exprs)
+		}
+		fv.Set(reflect.ValueOf(all))
+		return nil
+	case rangesType:
+		var list []struct{ From, To string }
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		var ranges []LetterRange
+		for _, r := range list {
+			if !isRangeLetter(r.From) || !isRangeLetter(r.To) {
+				return fmt.Errorf("bad letter range %s-%s", r.From, r.To)
+			}
+			ranges = append(ranges, LetterRange{From: r.From[0], To: r.To[0]})
+		}
+		fv.Set(reflect.ValueOf(ranges))
+		return nil
+	case numType:
+		return decodeName(fv, data, numTypeNames)
+	case modeType:
+		return decodeName(fv, data, fileModeNames)
+	}
+	if fv.Kind() == reflect.Interface { // ExtStmt.Data
+		return fmt.Errorf("extension data cannot be restored")
+	}
+	return json.Unmarshal(data, fv.Addr().Interface())
+}
+
+func decodeExprs(data []byte) ([]Expr, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	var exprs []Expr
+	for _, item := range list {
+		e, err := decodeExpr(item)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
+
+// decodeName sets fv to the index of its JSON string in names.
+func decodeName(fv reflect.Value, data []byte, names []string) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for i, n := range names {
+		if n == name {
+			fv.Set(reflect.ValueOf(i).Convert(fv.Type()))
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown name %q", name)
+}
+
+func isRangeLetter(s string) bool {
+	return len(s) == 1 && s[0] >= 'A' && s[0] <= 'Z'
+}
@@ -0,0 +1,202 @@
+/**************************************************************/
+/*
+   astjson_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// statementsProg has every kind of statement. It is only parsed.
+const statementsProg = `10 REM ALL STATEMENTS
+20 DEFINT I-K
+30 OPTION DECIMAL
+50 DIM A(3), B$(2, 2)
+60 LET A(1) = -ABS(2) + LEN("AB")
+70 PRINT USING "##.#"; A(1)
+80 INPUT N, M$
+90 IF N >= 1 THEN 110
+95 IF N < 0 THEN PRINT "SMALL"
+100 ON ERROR GOTO 200
+110 GOSUB 300
+115 IF N = 0 THEN GOTO 310
+120 RANDOMIZE 3
+130 OPEN "OUT.TXT" FOR OUTPUT AS #1
+140 PRINT #1, A(1)
+150 CLOSE #1
+155 LINE INPUT #3, L$
+157 RSET S$ = L$
+160 OPEN "REC.DAT" FOR RANDOM AS #2 LEN = 8
+170 FIELD #2, 4 AS R$, 4 AS S$
+180 LSET R$ = "AB"
+190 PUT #2, 1
+195 GET #2, 1
+200 RESUME NEXT
+205 CSVREAD "A.CSV" INTO A(), B$() HEADER COUNT N
+210 CSVWRITE "A.CSV" FROM A()
+220 SNAPSHOT "S.JSON"
+225 RESUME SNAPSHOT "S.JSON"
+230 ERROR 5
+300 RETURN
+310 END
+`
+
+func TestASTRoundTrip(t *testing.T) {
+	srcs := map[string]string{"Statements": statementsProg}
+	for _, p := range enginePrograms {
+		srcs[p.name] = p.src
+	}
+	for name, src := range srcs {
+		t.Run(name, func(t *testing.T) {
+			prog := parseSource(t, src)
+			for line, stmt := range prog.Stmts {
+				prog.Source[line] = stmt.String() // as ReadAST lists it
+			}
+			var first bytes.Buffer
+			if err := WriteAST(&first, prog); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadAST(bytes.NewReader(first.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var second bytes.Buffer
+			if err := WriteAST(&second, got); err != nil {
+				t.Fatal(err)
+			}
+			if first.String() != second.String() {
+				t.Errorf("JSON changed on round trip:\n%s\n---\n%s", &first, &second)
+			}
+			if name == "Statements" {
+				return
+			}
+			if want, out := runWith(prog), runWith(got); out != want {
+				t.Errorf("output = %q, want %q", out, want)
+			}
+		})
+	}
+}
+
+func TestASTExtension(t *testing.T) {
+	exec := func(it *Interpreter, s *ExtStmt, args []Value) error {
+		_, err := fmt.Fprintln(it.out, s.Keyword, s.Data, len(args))
+		return err
+	}
+	if err := RegisterStatement("ASTNOTE", nil, exec); err != nil {
+		t.Fatal(err)
+	}
+	err := RegisterStatement("ASTTAG", func(p *Parser, s *ExtStmt) {
+		p.Next()
+		s.Data = p.Cur().Literal
+	}, exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAST(&buf, parseSource(t, "10 ASTNOTE 1, 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	prog, err := ReadAST(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := runWith(prog), "ASTNOTE <nil> 2\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteAST(&buf, parseSource(t, "10 ASTTAG RED\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"data": "RED"`) {
+		t.Errorf("data not written:\n%s", &buf)
+	}
+	if _, err := ReadAST(&buf); err == nil || !strings.Contains(err.Error(), "extension data cannot be restored") {
+		t.Errorf("error = %v, want extension data cannot be restored", err)
+	}
+}
+
+func TestReadASTSource(t *testing.T) {
+	doc := `{"version": 1, "lines": [{"line": 10, "src": "PRINT 2", "stmt": {"kind": "PrintStmt", "exprs": [{"kind": "NumberLit", "value": 1}]}}]}`
+	prog, err := ReadAST(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := prog.Source[10]; got != "PRINT 1" {
+		t.Errorf("Source[10] = %q, want %q", got, "PRINT 1")
+	}
+}
+
+func TestASTMembers(t *testing.T) {
+	tests := []struct {
+		src    string
+		member string
+		want   any
+	}{
+		{"50 GOTO 10", "target", 10.0},
+		{"50 GOSUB 10", "target", 10.0},
+		{"50 ON ERROR GOTO 10", "target", 10.0},
+		{"50 RESUME 10", "target", 10.0},
+		{"50 RESUME NEXT", "next", true},
+		{"50 LINE INPUT #1, A$", "lineInput", true},
+		{"50 GOTO 10", "line", 50.0},
+		{"50 LINE INPUT #1, A$", "line", 50.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src+"/"+tt.member, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteAST(&buf, parseSource(t, tt.src+"\n")); err != nil {
+				t.Fatal(err)
+			}
+			var doc struct {
+				Lines []struct {
+					Stmt map[string]any `json:"stmt"`
+				} `json:"lines"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Lines[0].Stmt[tt.member]; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.member, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadASTErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"Version", `{"version": 2, "lines": []}`, "unsupported version 2"},
+		{"NoStmt", `{"version": 1, "lines": [{"line": 10}]}`, "missing line number or statement"},
+		{"NoLine", `{"version": 1, "lines": [{"stmt": {"kind": "EndStmt"}}]}`, "missing line number or statement"},
+		{"Kind", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "GotoStatement"}}]}`, `unknown node kind "GotoStatement"`},
+		{"NotStmt", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "NumberLit", "value": 1}}]}`, "is not a statement"},
+		{"NotExpr", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "PrintStmt", "exprs": [{"kind": "EndStmt"}]}}]}`, "is not an expression"},
+		{"Missing", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "LetStmt", "target": {"kind": "VarRef", "name": "A"}}}]}`, "LetStmt: missing"},
+		{"Then", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "IfStmt", "cond": {"kind": "NumberLit", "value": 1}}}]}`, "missing thenStmt"},
+		{"DimBounds", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "DimStmt", "names": ["A", "B"], "bounds": [[{"kind": "NumberLit", "value": 3}]]}}]}`, "2 names but 1 bounds"},
+		{"FieldWidths", `{"version": 1, "lines": [{"line": 10, "stmt": {"kind": "FieldStmt", "file": {"kind": "NumberLit", "value": 1}, "names": ["R$"]}}]}`, "1 names but 0 widths"},
+		{"Syntax", `{"version": 1, "lines": [`, "ast: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAST(strings.NewReader(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/**************************************************************/
+/*
+   basic.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+// Package basic implements a small line-numbered BASIC interpreter.
+//
+// A program is parsed with ParseProgram, or built line by line with
+// Program.EnterLine, and run by an Interpreter:
+//
+//	prog, err := basic.ParseProgram(strings.NewReader(`10 PRINT "HELLO"`))
+//	if err != nil {
+//		return err
+//	}
+//	it := basic.NewInterpreter(basic.WithProgram(prog), basic.WithStdout(w))
+//	err = it.Run(ctx)
+package basic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is returned for a line that does not parse.
+type SyntaxError struct {
+	Line int // line number
+	Errs []string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Syntax error at line %d: %s", e.Line, strings.Join(e.Errs, "; "))
+}
+
+// ParseProgram reads line-numbered statements, one per line, as they
+// would be typed into the REPL.
+func ParseProgram(r io.Reader) (*Program, error) {
+	prog := NewProgram()
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineNo, rest, ok := SplitLineNumber(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing line number", n)
+		}
+		if err := prog.EnterLine(lineNo, rest); err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
+
+// EnterLine parses src and stores it as line lineNo. An empty src deletes
+// the line.
+func (p *Program) EnterLine(lineNo int, src string) error {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		p.DeleteLine(lineNo)
+		return nil
+	}
+	stmt, errs := parseOneStatement(src)
+	if len(errs) > 0 {
+		return &SyntaxError{Line: lineNo, Errs: errs}
+	}
+	p.SetLine(lineNo, src, stmt)
+	return nil
+}
+
+func parseOneStatement(src string) (Stmt, []string) {
+	p := NewParser(src)
+	stmt := p.ParseStatement()
+	if stmt == nil {
+		return nil, p.Errors()
+	}
+
+	if p.peekTok.Type != EOF {
+		if p.curTok.Type != EOF {
+
+		}
+	}
+	if len(p.Errors()) > 0 {
+		return nil, p.Errors()
+	}
+	return stmt, nil
+}
+
+// SplitLineNumber splits the leading line number off s. ok is false if s
+// does not start with a positive line number.
+func SplitLineNumber(s string) (lineNo int, rest string, ok bool) {
+	i := 0
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	start := i
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	if i == start {
+		return 0, "", false
+	}
+
+	if i < len(s) && s[i] != ' ' && s[i] != '\t' {
+		return 0, "", false
+	}
+	n, err := parseIntStrict(s[start:i])
+	if err != nil || n <= 0 {
+		return 0, "", false
+	}
+	return n, s[i:], true
+}
+
+func parseIntStrict(s string) (int, error) {
+	if strings.Contains(s, ",") {
+		return 0, fmt.Errorf("must be integer")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,161 @@
+/**************************************************************/
+/*
+   builtins.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type builtinFunc func(it *Interpreter, args []Value) (Value, error)
+
+var builtinFuncs = map[string]builtinFunc{
+	"EOF": func(it *Interpreter, args []Value) (Value, error) {
+		return it.eof(args)
+	},
+	"MKI$": mki,
+	"MKS$": mks,
+	"MKD$": mkd,
+	"CVI":  cvi,
+	"CVS":  cvs,
+	"CVD":  cvd,
+	"ERR": func(it *Interpreter, args []Value) (Value, error) {
+		return NumberValue(float64(it.errCode)), nil
+	},
+	"ERL": func(it *Interpreter, args []Value) (Value, error) {
+		return NumberValue(float64(it.errLine)), nil
+	},
+	"RND": func(it *Interpreter, args []Value) (Value, error) {
+		if len(args) > 1 {
+			return Value{}, newError(ErrIllegalFunction, "RND takes at most 1 argument")
+		}
+		if len(args) == 0 {
+			return NumberValue(it.nextRnd()), nil
+		}
+		if args[0].Kind != ValNumber {
+			return Value{}, newError(ErrTypeMismatch, "RND requires number")
+		}
+		return NumberValue(it.rndFunc(args[0].Num)), nil
+	},
+	"STR$": func(it *Interpreter, args []Value) (Value, error) {
+		if len(args) != 1 {
+			return Value{}, newError(ErrIllegalFunction, "STR$ takes 1 argument")
+		}
+		if args[0].Kind != ValNumber {
+			return Value{}, newError(ErrTypeMismatch, "STR$ requires number")
+		}
+		return StringValue(it.formatNumber(args[0])), nil
+	},
+	"TIMER": func(it *Interpreter, args []Value) (Value, error) {
+		now := time.Now()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return NumberValue(now.Sub(midnight).Seconds()), nil
+	},
+}
+
+func (it *Interpreter) evalFunc(x *FuncExpr) (Value, error) {
+	args, err := it.evalArgs(x.Args)
+	if err != nil {
+		return Value{}, err
+	}
+	return it.callFunc(x.Name, args)
+}
+
+func (it *Interpreter) evalArgs(exprs []Expr) ([]Value, error) {
+	args := make([]Value, 0, len(exprs))
+	for _, a := range exprs {
+		v, err := it.evalExpr(a)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
+
+// callFunc calls a built-in or host function; any other name is an array.
+func (it *Interpreter) callFunc(name string, args []Value) (Value, error) {
+	if fn, ok := builtinFuncs[name]; ok {
+		return fn(it, args)
+	}
+	if hf, ok := it.funcs[name]; ok {
+		return hf.call(args)
+	}
+	return it.element(name, args)
+}
+
+// HostFunc is a Go function callable from BASIC. args match the parameter
+// kinds given to RegisterFunc.
+type HostFunc func(args []Value) (Value, error)
+
+type hostFunc struct {
+	name   string
+	params []ValueKind
+	result ValueKind
+	fn     HostFunc
+}
+
+// RegisterFunc makes fn callable from BASIC as name(args). A string
+// result requires a name ending in "$", as for variables. Errors returned
+// by fn become trappable BASIC errors.
+func (it *Interpreter) RegisterFunc(name string, params []ValueKind, result ValueKind, fn HostFunc) error {
+	if !validFuncName(name) {
+		return fmt.Errorf("invalid function name %q", name)
+	}
+	if _, ok := builtinFuncs[name]; ok {
+		return fmt.Errorf("%s is a built-in function", name)
+	}
+	if (result == ValString) != strings.HasSuffix(name, "$") {
+		return fmt.Errorf("%s: result kind does not match the name", name)
+	}
+	if it.funcs == nil {
+		it.funcs = map[string]*hostFunc{}
+	}
+	it.funcs[name] = &hostFunc{name: name, params: append([]ValueKind(nil), params...), result: result, fn: fn}
+	return nil
+}
+
+func (hf *hostFunc) call(args []Value) (Value, error) {
+	if len(args) != len(hf.params) {
+		return Value{}, newError(ErrIllegalFunction, "%s takes %d arguments, got %d", hf.name, len(hf.params), len(args))
+	}
+	for i, a := range args {
+		if a.Kind != hf.params[i] {
+			return Value{}, newError(ErrTypeMismatch, "type mismatch: argument %d of %s", i+1, hf.name)
+		}
+	}
+	v, err := hf.fn(args)
+	if err != nil {
+		return Value{}, hostError(hf.name, err)
+	}
+	if v.Kind != hf.result {
+		return Value{}, newError(ErrTypeMismatch, "type mismatch: result of %s", hf.name)
+	}
+	return v, nil
+}
+
+// hostError turns an error returned by embedder code into a trappable
+// BASIC error.
+func hostError(name string, err error) error {
+	var be *BasicError
+	if errors.As(err, &be) {
+		return be
+	}
+	return &BasicError{Code: ErrIllegalFunction, Msg: fmt.Sprintf("%s: %v", name, err), Err: err}
+}
+
+// validFuncName reports whether name lexes as a single identifier, so a
+// program can call it.
+func validFuncName(name string) bool {
+	l := NewLexer(name)
+	tok := l.NextToken()
+	return tok.Type == IDENT && tok.Literal == name && l.NextToken().Type == EOF
+}
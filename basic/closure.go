@@ -0,0 +1,305 @@
+/**************************************************************/
+/*
+   closure.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+// stmtFunc runs the statement at index pc of lp and returns the index of
+// the next statement, like execStmt.
+type stmtFunc func(it *Interpreter, lp *Linked, pc int) (int, bool, error)
+
+type exprFunc func(it *Interpreter) (Value, error)
+
+// numFunc evaluates an expression that always yields a number when it
+// succeeds, without boxing the result in a Value.
+type numFunc func(it *Interpreter) (float64, error)
+
+// compileStmt turns stmt into closures with its operators and variable
+// slots bound in advance. Statements without a specialised form run
+// through execStmt.
+func compileStmt(stmt Stmt) stmtFunc {
+	switch s := stmt.(type) {
+	case *RemStmt:
+		return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+			return pc + 1, false, nil
+		}
+
+	case *LetStmt:
+		if s.Index != nil {
+			break
+		}
+		slot := s.Slot
+		val := compileExpr(s.Expr)
+		return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+			v, err := val(it)
+			if err != nil {
+				return 0, false, err
+			}
+			if err := it.setSlot(slot, v); err != nil {
+				return 0, false, err
+			}
+			return pc + 1, false, nil
+		}
+
+	case *PrintStmt:
+		if s.Using != nil || s.File != nil {
+			break
+		}
+		vals := make([]exprFunc, len(s.Exprs))
+		for i, e := range s.Exprs {
+			vals[i] = compileExpr(e)
+		}
+		return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+			out := make([]Value, len(vals))
+			for i, val := range vals {
+				v, err := val(it)
+				if err != nil {
+					return 0, false, err
+				}
+				out[i] = v
+			}
+			if err := it.print(it.out, out); err != nil {
+				return 0, false, err
+			}
+			return pc + 1, false, nil
+		}
+
+	case *IfStmt:
+		var then stmtFunc
+		if s.HasLine {
+			then = func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+				return lp.Target[pc], false, nil
+			}
+		} else {
+			then = compileStmt(s.ThenStmt)
+		}
+		if isNumeric(s.Cond) {
+			num := compileNum(s.Cond)
+			cond := func(it *Interpreter) (float64, error) {
+				if it.dec != nil {
+					v, err := it.evalExpr(s.Cond)
+					return v.Num, err
+				}
+				return num(it)
+			}
+			return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+				c, err := cond(it)
+				if err != nil {
+					return 0, false, err
+				}
+				if it.Coverage != nil {
+					it.Coverage.branch(lp.Lines[pc], ifDepth(lp.Stmts[pc], s), c != 0)
+				}
+				if c == 0 {
+					return pc + 1, false, nil
+				}
+				return then(it, lp, pc)
+			}
+		}
+		cond := compileExpr(s.Cond)
+		return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+			c, err := cond(it)
+			if err != nil {
+				return 0, false, err
+			}
+			if c.Kind != ValNumber {
+				return 0, false, newError(ErrTypeMismatch, "IF condition must be numeric")
+			}
+			if it.Coverage != nil {
+				it.Coverage.branch(lp.Lines[pc], ifDepth(lp.Stmts[pc], s), c.Num != 0)
+			}
+			if c.Num == 0 {
+				return pc + 1, false, nil
+			}
+			return then(it, lp, pc)
+		}
+
+	case *GotoStmt:
+		return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+			return lp.Target[pc], false, nil
+		}
+	}
+
+	return func(it *Interpreter, lp *Linked, pc int) (int, bool, error) {
+		return it.execStmt(stmt, lp, pc)
+	}
+}
+
+func compileExpr(e Expr) exprFunc {
+	if isNumeric(e) {
+		num := compileNum(e)
+		lit := isConst(e)
+		return func(it *Interpreter) (Value, error) {
+			if it.dec != nil {
+				return it.evalExpr(e)
+			}
+			n, err := num(it)
+			if err != nil {
+				return Value{}, err
+			}
+			return Value{Kind: ValNumber, Num: n, lit: lit}, nil
+		}
+	}
+	switch x := e.(type) {
+	case *StringLit:
+		v := StringValue(x.Value)
+		return func(it *Interpreter) (Value, error) { return v, nil }
+	case *VarRef:
+		slot := x.Slot
+		return func(it *Interpreter) (Value, error) { return it.Env.load(slot), nil }
+	case *FuncExpr:
+		name := x.Name
+		args := make([]exprFunc, len(x.Args))
+		for i, a := range x.Args {
+			args[i] = compileExpr(a)
+		}
+		return func(it *Interpreter) (Value, error) {
+			vals := make([]Value, len(args))
+			for i, arg := range args {
+				v, err := arg(it)
+				if err != nil {
+					return Value{}, err
+				}
+				vals[i] = v
+			}
+			return it.callFunc(name, vals)
+		}
+	}
+	return func(it *Interpreter) (Value, error) { return it.evalExpr(e) }
+}
+
+// compileNum compiles an expression for which isNumeric holds.
+func compileNum(e Expr) numFunc {
+	switch x := e.(type) {
+	case *NumberLit:
+		n := x.Value
+		return func(it *Interpreter) (float64, error) { return n, nil }
+
+	case *VarRef:
+		slot := x.Slot
+		return func(it *Interpreter) (float64, error) { return it.Env.num[slot], nil }
+
+	case *UnaryExpr:
+		if !isNumeric(x.Rhs) || (x.Op != "-" && x.Op != "+") {
+			break
+		}
+		rhs := compileNum(x.Rhs)
+		if x.Op == "+" {
+			return rhs
+		}
+		return func(it *Interpreter) (float64, error) {
+			n, err := rhs(it)
+			return -n, err
+		}
+
+	case *BinaryExpr:
+		if isNumeric(x.Lhs) && isNumeric(x.Rhs) {
+			if fn := compileNumBinary(x.Op, compileNum(x.Lhs), compileNum(x.Rhs)); fn != nil {
+				return fn
+			}
+		}
+		op := x.Op
+		lhs := compileExpr(x.Lhs)
+		rhs := compileExpr(x.Rhs)
+		return func(it *Interpreter) (float64, error) {
+			l, err := lhs(it)
+			if err != nil {
+				return 0, err
+			}
+			r, err := rhs(it)
+			if err != nil {
+				return 0, err
+			}
+			v, err := it.binary(op, l, r)
+			return v.Num, err
+		}
+	}
+
+	return func(it *Interpreter) (float64, error) {
+		v, err := it.evalExpr(e)
+		return v.Num, err
+	}
+}
+
+// compileNumBinary returns a closure for op applied to two numbers, or nil
+// if op is not a numeric operator. It must agree with evalBinary.
+func compileNumBinary(op string, lhs, rhs numFunc) numFunc {
+	operands := func(it *Interpreter) (float64, float64, error) {
+		l, err := lhs(it)
+		if err != nil {
+			return 0, 0, err
+		}
+		r, err := rhs(it)
+		return l, r, err
+	}
+	truth := func(ok bool) float64 {
+		if ok {
+			return 1
+		}
+		return 0
+	}
+
+	switch op {
+	case "+":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return l + r, err
+		}
+	case "-":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return l - r, err
+		}
+	case "*":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return l * r, err
+		}
+	case "/":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			if err != nil {
+				return 0, err
+			}
+			if r == 0 {
+				return 0, newError(ErrDivisionByZero, "division by zero")
+			}
+			return l / r, nil
+		}
+	case "=":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return truth(l == r), err
+		}
+	case "<>":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return truth(l != r), err
+		}
+	case "<":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return truth(l < r), err
+		}
+	case "<=":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return truth(l <= r), err
+		}
+	case ">":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return truth(l > r), err
+		}
+	case ">=":
+		return func(it *Interpreter) (float64, error) {
+			l, r, err := operands(it)
+			return truth(l >= r), err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+/**************************************************************/
+/*
+   closure_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// enginePrograms are run on every engine, with and without OPTIMIZE.
+var enginePrograms = []struct {
+	name string
+	src  string
+	want string
+}{
+	{"Primes", primesProg, " 62\n"},
+	{"Gosub", gosubProg, " 12502500\n"},
+	{"Variables", variablesProg, " 49995000  25000001 -83320832498\n"},
+	{"Comparisons", `10 A = 3
+20 IF A >= 3 THEN PRINT "GE"
+30 IF A > 3 THEN PRINT "GT"
+40 IF A <= 3 THEN PRINT "LE"
+50 IF A <> 3 THEN PRINT "NE"
+60 IF "AB" = "AB" THEN 80
+70 PRINT "UNREACHED"
+80 PRINT -A + 2 * 3 - 4 / 2, "S"
+`, `GE
+LE
+ 1 S
+`},
+	{"NestedGosub", `10 N = 0
+20 GOSUB 100
+30 GOSUB 100
+40 PRINT "N =", N
+50 END
+100 N = N + 1
+110 GOSUB 200
+120 RETURN
+200 N = N * 10
+210 RETURN
+`, "N =  110\n"},
+	{"OnError", `10 ON ERROR GOTO 100
+20 A = 1 / 0
+30 PRINT "AFTER", A
+40 ERROR 5
+50 PRINT "NEXT"
+60 END
+100 PRINT "ERR", ERR, "AT", ERL
+110 RESUME NEXT
+`, `ERR  11 AT  20
+AFTER  0
+ERR  5 AT  40
+NEXT
+`},
+	{"RuntimeError", `10 PRINT "START"
+20 GOTO 99
+`, "runtime error at line 20: undefined line 99\n"},
+	{"MonteCarlo", `10 RANDOMIZE 1
+20 K = 0
+30 X = RND(1)
+40 Y = RND(1)
+50 IF X * X + Y * Y <= 1 THEN N = N + 1
+60 K = K + 1
+70 IF K < 5000 THEN 30
+80 PRINT 4 * N / K
+`, " 3.1848\n"},
+	{"TypedArithmetic", `10 C% = 5
+20 PRINT C% + 1, 3 * C%, C% / 2, C% \ 2, 7 \ 2
+30 D! = 1 / 3
+40 PRINT D!, 1 / 3, D! * 3
+50 E% = 2.5
+60 F% = 3.5
+70 PRINT E%, F%
+80 A = 0.5
+90 B% = 3
+100 G = 1
+110 H% = 20000
+120 PRINT (1 + A) * B%, (1 + G) / B%, (1 + G) * H%
+130 PRINT C% * 0.5, 1.5 * C%
+`, ` 6  15  2.5  2  3
+ .33333334  .3333333333333333  1
+ 2  4
+ 4.5  .6666666666666666  40000
+ 2.5  7.5
+`},
+	{"DefTypes", `10 DEFINT I-K
+20 DEFSTR S
+30 I = 7.6
+40 S = "ABC"
+50 X = 7.6
+60 PRINT I, S, X, I / 2
+`, " 8 ABC  7.6  4\n"},
+	{"Decimal", `10 OPTION DECIMAL
+20 A = 0.1 + 0.2
+30 PRINT A, A = 0.3, 1 / 3
+`, " .3  1  .33333333333333333333\n"},
+	{"PrintUsing", `10 PRINT USING "##.##"; 3.14159, 2
+20 PRINT USING "&!"; "AB", "CD"
+30 PRINT USING "$$#,###.##"; 1234.5
+`, ` 3.14 2.00
+ABC
+ $1,234.50
+`},
+	{"Files", `10 OPEN "T.TXT" FOR OUTPUT AS #1
+20 PRINT #1, "HELLO", 42
+30 CLOSE #1
+40 OPEN "T.TXT" FOR INPUT AS #1
+50 LINE INPUT #1, L$
+60 CLOSE #1
+70 PRINT L$
+`, "HELLO  42\n"},
+	{"Sieve", `10 N = 2000
+20 DIM F(N)
+30 I = 2
+40 IF F(I) <> 0 THEN 100
+50 C = C + 1
+60 J = I + I
+70 IF J > N THEN 100
+80 F(J) = 1
+90 J = J + I
+95 GOTO 70
+100 I = I + 1
+110 IF I <= N THEN 40
+120 PRINT C
+`, " 303\n"},
+	{"Arrays", `10 DIM A(3, 2), N$(2)
+20 A(3, 2) = 6
+30 N$(1) = "ONE"
+40 B(10) = 1
+50 PRINT A(3, 2), N$(1), B(10), A(0, 0)
+60 PRINT B(11)
+`, ` 6 ONE  1  0
+runtime error at line 60: subscript out of range: B
+`},
+	{"UndefinedArray", `10 ON ERROR GOTO 100
+20 PRINT C(1)
+30 C(2) = 5
+40 PRINT C(1), C(2)
+50 END
+100 PRINT ERR, ERL
+110 RESUME NEXT
+`, ` 18  20
+ 0  5
+`},
+}
+
+// runSource runs src on engine and returns what it printed, followed by
+// the error of the run if any.
+func runSource(tb testing.TB, src string, engine Engine, optimize bool) string {
+	tb.Helper()
+	prog := parseSource(tb, src)
+	prog.SetOptimize(optimize)
+	return runWith(prog, WithEngine(engine))
+}
+
+// runWith runs prog configured by opts, with empty standard input and an
+// empty MemFS unless opts set them, and returns what it printed, followed by the error of the
+// run if any.
+func runWith(prog *Program, opts ...Option) string {
+	var out bytes.Buffer
+	opts = append([]Option{
+		WithProgram(prog),
+		WithStdin(strings.NewReader("")),
+		WithStdout(&out),
+		WithFS(NewMemFS()),
+	}, opts...)
+	if err := NewInterpreter(opts...).Run(context.Background()); err != nil {
+		out.WriteString(err.Error() + "\n")
+	}
+	return out.String()
+}
+
+// TestEngines checks that the VM and the closures print what the tree
+// walker prints.
+func TestEngines(t *testing.T) {
+	engines := []struct {
+		name   string
+		engine Engine
+	}{{"Tree", EngineTree}, {"VM", EngineVM}, {"Closure", EngineClosure}}
+	for _, p := range enginePrograms {
+		for _, e := range engines {
+			for _, optimize := range []bool{false, true} {
+				name := p.name + "/" + e.name
+				if optimize {
+					name += "/Optimize"
+				}
+				t.Run(name, func(t *testing.T) {
+					if got := runSource(t, p.src, e.engine, optimize); got != p.want {
+						t.Errorf("got\n%s\nwant\n%s", got, p.want)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+/**************************************************************/
+/*
+   compile.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Opcode byte
+
+const (
+	OpStmt      Opcode = iota // start of statement A
+	OpPushNum                 // push Nums[A]
+	OpPushStr                 // push Strs[A]
+	OpLoad                    // push variable A
+	OpStore                   // pop into variable A
+	OpNeg                     // unary -
+	OpPos                     // unary +
+	OpBinary                  // apply binaryOps[A]
+	OpCall                    // call built-in Strs[A] with B arguments
+	OpPrint                   // print A values
+	OpInput                   // read INPUT for variable A and push it
+	OpJump                    // jump to A
+	OpJumpFalse               // pop IF condition, jump to A when 0; B is the IF depth
+	OpGosub                   // push statement B, jump to A
+	OpReturn                  // return from GOSUB
+	OpOnError                 // ON ERROR GOTO statement A (-1: off)
+	OpResume                  // RESUME Nodes[A]; B is the statement of RESUME line
+	OpRaise                   // pop ERROR code
+	OpExt                     // run extension statement Nodes[A] with B arguments
+	OpRandomize               // pop RANDOMIZE seed
+	OpExec                    // run Nodes[A] with execStmt; it must not jump
+	OpEnd                     // END
+	OpHalt                    // ran past the last line
+)
+
+var opNames = [...]string{
+	"STMT", "PUSHNUM", "PUSHSTR", "LOAD", "STORE", "NEG", "POS", "BINARY",
+	"CALL", "PRINT", "INPUT", "JUMP", "JUMPFALSE", "GOSUB", "RETURN",
+	"ONERROR", "RESUME", "RAISE", "EXT", "RANDOMIZE", "EXEC", "END", "HALT",
+}
+
+func (op Opcode) String() string {
+	if int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return fmt.Sprintf("OP(%d)", op)
+}
+
+var binaryOps = []string{"+", "-", "*", "/", "=", "<>", "<", "<=", ">", ">=", "\\"}
+
+const (
+	binAdd = iota
+	binSub
+	binMul
+)
+
+type Instr struct {
+	Op Opcode
+	A  int
+	B  int
+}
+
+// Bytecode is a Program compiled for the VM. Statements are numbered in
+// line order; jump operands are already resolved to code addresses and
+// variable operands are Env slots.
+type Bytecode struct {
+	Code  []Instr
+	Nums  []float64
+	Strs  []string
+	Nodes []Stmt // statements needed at run time (RESUME, extensions, OpExec)
+
+	Lines []int  // line number of each statement
+	Stmts []Stmt // each statement
+	Addr  []int  // statement index -> code address; Addr[len(Stmts)] is the final HALT
+}
+
+// Disassemble returns a readable listing of the code.
+func (bc *Bytecode) Disassemble() string {
+	var sb strings.Builder
+	for addr, in := range bc.Code {
+		fmt.Fprintf(&sb, "%04d %-9s %d %d\n", addr, in.Op, in.A, in.B)
+	}
+	return sb.String()
+}
+
+type compiler struct {
+	lp     *Linked
+	bc     *Bytecode
+	nums   map[float64]int
+	strs   map[string]int
+	fixups []int // instructions whose A is a statement index to resolve
+	err    error
+}
+
+// Compile translates prog to bytecode.
+func Compile(prog *Program) (*Bytecode, error) {
+	lp, err := prog.Link()
+	if err != nil {
+		return nil, err
+	}
+	return lp.Compile()
+}
+
+// Compile translates lp to bytecode. The result is kept until a line of
+// the program changes.
+func (lp *Linked) Compile() (*Bytecode, error) {
+	if lp.code != nil {
+		return lp.code, nil
+	}
+	bc := &Bytecode{
+		Lines: lp.Lines,
+		Stmts: lp.Stmts,
+		Addr:  make([]int, len(lp.Stmts)+1),
+	}
+	c := &compiler{
+		lp:   lp,
+		bc:   bc,
+		nums: map[float64]int{},
+		strs: map[string]int{},
+	}
+
+	for i, stmt := range lp.Stmts {
+		bc.Addr[i] = len(bc.Code)
+		c.emit(OpStmt, i, 0)
+		c.stmt(stmt, i)
+	}
+	bc.Addr[len(lp.Stmts)] = len(bc.Code)
+	c.emit(OpHalt, 0, 0)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	for _, at := range c.fixups {
+		bc.Code[at].A = bc.Addr[bc.Code[at].A]
+	}
+	lp.code = bc
+	return bc, nil
+}
+
+func (c *compiler) emit(op Opcode, a, b int) int {
+	c.bc.Code = append(c.bc.Code, Instr{Op: op, A: a, B: b})
+	return len(c.bc.Code) - 1
+}
+
+// jumpTo emits op to statement idx.
+func (c *compiler) jumpTo(op Opcode, idx int, b int) {
+	c.fixups = append(c.fixups, c.emit(op, idx, b))
+}
+
+func (c *compiler) str(s string) int {
+	if i, ok := c.strs[s]; ok {
+		return i
+	}
+	i := len(c.bc.Strs)
+	c.strs[s] = i
+	c.bc.Strs = append(c.bc.Strs, s)
+	return i
+}
+
+func (c *compiler) num(n float64) int {
+	if i, ok := c.nums[n]; ok {
+		return i
+	}
+	i := len(c.bc.Nums)
+	c.nums[n] = i
+	c.bc.Nums = append(c.bc.Nums, n)
+	return i
+}
+
+func (c *compiler) stmt(stmt Stmt, idx int) {
+	switch s := stmt.(type) {
+	case *RemStmt, *DefStmt:
+	case *LetStmt:
+		if s.Index != nil {
+			c.bc.Nodes = append(c.bc.Nodes, s)
+			c.emit(OpExec, len(c.bc.Nodes)-1, 0)
+			break
+		}
+		c.expr(s.Expr)
+		c.emit(OpStore, s.Slot, 0)
+	case *PrintStmt:
+		if s.Using != nil || s.File != nil {
+			c.bc.Nodes = append(c.bc.Nodes, s)
+			c.emit(OpExec, len(c.bc.Nodes)-1, 0)
+			break
+		}
+		for _, e := range s.Exprs {
+			c.expr(e)
+		}
+		c.emit(OpPrint, len(s.Exprs), 0)
+	case *InputStmt:
+		c.emit(OpInput, s.Slot, 0)
+		c.emit(OpStore, s.Slot, 0)
+	case *IfStmt:
+		c.expr(s.Cond)
+		c.jumpTo(OpJumpFalse, idx+1, ifDepth(c.lp.Stmts[idx], s))
+		if s.HasLine {
+			c.jumpTo(OpJump, c.lp.Target[idx], 0)
+		} else {
+			c.stmt(s.ThenStmt, idx)
+		}
+	case *GotoStmt:
+		c.jumpTo(OpJump, c.lp.Target[idx], 0)
+	case *GosubStmt:
+		c.jumpTo(OpGosub, c.lp.Target[idx], idx+1)
+	case *ReturnStmt:
+		c.emit(OpReturn, 0, 0)
+	case *OnErrorStmt:
+		c.emit(OpOnError, c.lp.Target[idx], 0)
+	case *ResumeStmt:
+		c.bc.Nodes = append(c.bc.Nodes, s)
+		c.emit(OpResume, len(c.bc.Nodes)-1, c.lp.Target[idx])
+	case *ErrorStmt:
+		c.expr(s.Code)
+		c.emit(OpRaise, 0, 0)
+	case *OptionStmt, *OpenStmt, *CloseStmt, *FileInputStmt, *FieldStmt, *RecordStmt, *LsetStmt, *DimStmt, *CSVStmt, *SnapshotStmt:
+		c.bc.Nodes = append(c.bc.Nodes, s)
+		c.emit(OpExec, len(c.bc.Nodes)-1, 0)
+	case *RandomizeStmt:
+		c.expr(s.Seed)
+		c.emit(OpRandomize, 0, 0)
+	case *ExtStmt:
+		for _, e := range s.Args {
+			c.expr(e)
+		}
+		c.bc.Nodes = append(c.bc.Nodes, s)
+		c.emit(OpExt, len(c.bc.Nodes)-1, len(s.Args))
+	case *EndStmt:
+		c.emit(OpEnd, 0, 0)
+	default:
+		c.err = newError(ErrSyntax, "unknown statement type %T", stmt)
+	}
+}
+
+func (c *compiler) expr(e Expr) {
+	switch x := e.(type) {
+	case *NumberLit:
+		c.emit(OpPushNum, c.num(x.Value), 0)
+	case *StringLit:
+		c.emit(OpPushStr, c.str(x.Value), 0)
+	case *VarRef:
+		c.emit(OpLoad, x.Slot, 0)
+	case *FuncExpr:
+		for _, a := range x.Args {
+			c.expr(a)
+		}
+		c.emit(OpCall, c.str(x.Name), len(x.Args))
+	case *UnaryExpr:
+		c.expr(x.Rhs)
+		if x.Op == "-" {
+			c.emit(OpNeg, 0, 0)
+		} else {
+			c.emit(OpPos, 0, 0)
+		}
+	case *BinaryExpr:
+		c.expr(x.Lhs)
+		c.expr(x.Rhs)
+		op := -1
+		for i, name := range binaryOps {
+			if name == x.Op {
+				op = i
+			}
+		}
+		if op < 0 {
+			c.err = newError(ErrSyntax, "unsupported operator %q", x.Op)
+		}
+		c.emit(OpBinary, op, 0)
+	default:
+		c.err = newError(ErrSyntax, "unknown expression type %T", e)
+	}
+}
@@ -0,0 +1,154 @@
+/**************************************************************/
+/*
+   coverage.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Coverage counts how often each line runs and which way each IF goes.
+// Set Interpreter.Coverage to a new Coverage to record a run; counts add
+// up over runs sharing one Coverage, e.g. one run per test input.
+type Coverage struct {
+	Name string // source file name used in LCOV output
+
+	hits     map[int]int
+	branches map[int][]BranchCount // per line, by IF nesting depth
+}
+
+// BranchCount is how often an IF condition was true (Taken) and false.
+type BranchCount struct {
+	Taken    int
+	NotTaken int
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{hits: map[int]int{}, branches: map[int][]BranchCount{}}
+}
+
+func (c *Coverage) hit(lineNo int) { c.hits[lineNo]++ }
+
+// branch records the result of the IF at depth in the THEN chain of line
+// lineNo.
+func (c *Coverage) branch(lineNo, depth int, taken bool) {
+	bs := c.branches[lineNo]
+	for len(bs) <= depth {
+		bs = append(bs, BranchCount{})
+	}
+	if taken {
+		bs[depth].Taken++
+	} else {
+		bs[depth].NotTaken++
+	}
+	c.branches[lineNo] = bs
+}
+
+// ifDepth returns how many IFs precede s in the THEN chain of top, the
+// statement of a line: 0 for the outer IF, 1 for IF ... THEN IF, etc.
+func ifDepth(top Stmt, s *IfStmt) int {
+	depth := 0
+	for top != Stmt(s) {
+		ifs, ok := top.(*IfStmt)
+		if !ok {
+			break
+		}
+		top = ifs.ThenStmt
+		depth++
+	}
+	return depth
+}
+
+// Hits returns how often line lineNo ran.
+func (c *Coverage) Hits(lineNo int) int { return c.hits[lineNo] }
+
+// Branches returns the counts of the IFs on line lineNo, outer one first.
+// It is shorter than the number of IFs if the inner ones never ran.
+func (c *Coverage) Branches(lineNo int) []BranchCount {
+	return c.branches[lineNo]
+}
+
+// ifCount returns the number of IFs in the THEN chain of stmt.
+func ifCount(stmt Stmt) int {
+	n := 0
+	for {
+		s, ok := stmt.(*IfStmt)
+		if !ok {
+			return n
+		}
+		n++
+		if s.HasLine {
+			return n
+		}
+		stmt = s.ThenStmt
+	}
+}
+
+// List writes prog like LIST with the hit count of each line in the
+// margin ("#####" for lines that never ran) and the branch counts of IF
+// lines at the end.
+func (c *Coverage) List(w io.Writer, prog *Program) error {
+	for _, ln := range prog.OrderedLines() {
+		count := "#####"
+		if n := c.hits[ln]; n > 0 {
+			count = fmt.Sprint(n)
+		}
+		line := fmt.Sprintf("%8s  %d %s", count, ln, prog.Source[ln])
+		bs := c.branches[ln]
+		for i := 0; i < ifCount(prog.Stmts[ln]); i++ {
+			var b BranchCount
+			if i < len(bs) {
+				b = bs[i]
+			}
+			line += fmt.Sprintf("  [taken %d, not taken %d]", b.Taken, b.NotTaken)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteLCOV writes the coverage of prog as an LCOV tracefile. Each IF is
+// a block with branch 0 taken and branch 1 not taken.
+func (c *Coverage) WriteLCOV(w io.Writer, prog *Program) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TN:\nSF:%s\n", c.Name)
+	var lf, lh, brf, brh int
+	for _, ln := range prog.OrderedLines() {
+		bs := c.branches[ln]
+		for i := 0; i < ifCount(prog.Stmts[ln]); i++ {
+			if i >= len(bs) {
+				fmt.Fprintf(&b, "BRDA:%d,%d,0,-\nBRDA:%d,%d,1,-\n", ln, i, ln, i)
+				brf += 2
+				continue
+			}
+			for j, n := range []int{bs[i].Taken, bs[i].NotTaken} {
+				fmt.Fprintf(&b, "BRDA:%d,%d,%d,%d\n", ln, i, j, n)
+				brf++
+				if n > 0 {
+					brh++
+				}
+			}
+		}
+	}
+	fmt.Fprintf(&b, "BRF:%d\nBRH:%d\n", brf, brh)
+	for _, ln := range prog.OrderedLines() {
+		n := c.hits[ln]
+		fmt.Fprintf(&b, "DA:%d,%d\n", ln, n)
+		lf++
+		if n > 0 {
+			lh++
+		}
+	}
+	fmt.Fprintf(&b, "LF:%d\nLH:%d\nend_of_record\n", lf, lh)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
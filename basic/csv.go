@@ -0,0 +1,152 @@
+/**************************************************************/
+/*
+   csv.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// csvRead runs CSVREAD. Column i of the file goes to the i-th array,
+// which is redimensioned to the number of rows, with row n at (n). The
+// arrays are left unchanged when the file or one of its values has an
+// error.
+func (it *Interpreter) csvRead(s *CSVStmt) error {
+	name, err := it.csvName(s)
+	if err != nil {
+		return err
+	}
+	f, err := it.FS.Open(name)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	if s.Header {
+		if _, err := cr.Read(); err != nil && !errors.Is(err, io.EOF) {
+			return csvError(err)
+		}
+	}
+	cols := make([][]Value, len(s.Arrays))
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return csvError(err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rec) < len(s.Arrays) {
+			return newError(ErrIllegalFunction, "CSVREAD: line %d has %d fields, want %d", line, len(rec), len(s.Arrays))
+		}
+		for i, array := range s.Arrays {
+			v := StringValue(rec[i])
+			if isStr, _ := it.Env.syms.typeOf(array); !isStr {
+				if v, err = it.parseNumber(strings.TrimSpace(rec[i])); err != nil {
+					_, col := cr.FieldPos(i)
+					return newError(ErrTypeMismatch, "CSVREAD: line %d, column %d: %q is not a number for %s()", line, col, rec[i], array)
+				}
+			}
+			cols[i] = append(cols[i], v)
+		}
+	}
+
+	n := 0
+	if len(cols) > 0 {
+		n = len(cols[0])
+	}
+	// fill new arrays first so that a value that cannot be stored leaves
+	// all of them unchanged
+	arrays := make([]*array, len(s.Arrays))
+	for i, name := range s.Arrays {
+		isStr, typ := it.Env.syms.typeOf(name)
+		arrays[i] = newArray(isStr, typ, []int{n})
+		for row, v := range cols[i] {
+			if err := it.storeElement(name, arrays[i], row+1, v); err != nil {
+				return err
+			}
+		}
+	}
+	for i, name := range s.Arrays {
+		it.Env.putArray(name, arrays[i])
+	}
+	if s.Count != "" {
+		return it.setSlot(s.Slot, NumberValue(float64(n)))
+	}
+	return nil
+}
+
+// csvWrite runs CSVWRITE: elements 1 to the bound of one-dimensional
+// arrays of equal size, with their names as the header row.
+func (it *Interpreter) csvWrite(s *CSVStmt) error {
+	arrays := make([]*array, len(s.Arrays))
+	for i, name := range s.Arrays {
+		a, ok := it.Env.arrays[name]
+		if !ok {
+			return newError(ErrIllegalFunction, "CSVWRITE: %s() is not dimensioned", name)
+		}
+		if len(a.dims) != 1 {
+			return newError(ErrSubscriptRange, "CSVWRITE: %s() has %d dimensions", name, len(a.dims))
+		}
+		if i > 0 && a.dims[0] != arrays[0].dims[0] {
+			return newError(ErrSubscriptRange, "CSVWRITE: %s() and %s() differ in size", s.Arrays[0], name)
+		}
+		arrays[i] = a
+	}
+	name, err := it.csvName(s)
+	if err != nil {
+		return err
+	}
+	f, err := it.FS.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return fileError(err)
+	}
+
+	cw := csv.NewWriter(f)
+	if s.Header {
+		cw.Write(s.Arrays)
+	}
+	rec := make([]string, len(arrays))
+	for row := 1; len(arrays) > 0 && row <= arrays[0].dims[0]; row++ {
+		for i, a := range arrays {
+			rec[i] = a.vals[row].String()
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return errors.Join(cw.Error(), f.Close())
+}
+
+// csvName evaluates the file name of s.
+func (it *Interpreter) csvName(s *CSVStmt) (string, error) {
+	v, err := it.evalExpr(s.Name)
+	if err != nil {
+		return "", err
+	}
+	if v.Kind != ValString {
+		return "", newError(ErrTypeMismatch, "%s requires a file name", s.keyword())
+	}
+	if it.FS == nil {
+		return "", newError(ErrPathAccess, "no file system")
+	}
+	return v.Str, nil
+}
+
+// csvError returns the BASIC error for malformed CSV.
+func csvError(err error) error {
+	be := newError(ErrIllegalFunction, "CSVREAD: %v", err)
+	be.Err = err
+	return be
+}
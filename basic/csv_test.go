@@ -0,0 +1,88 @@
+/**************************************************************/
+/*
+   csv_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string // before the run
+		src       string
+		want      string
+		wantFiles map[string]string // after the run
+	}{
+		{"Read", map[string]string{"D.CSV": "NAME,SCORE\n\"Smith, J\",90\n\"say \"\"hi\"\"\", 7.5\n"}, `10 CSVREAD "D.CSV" INTO N$(), S() HEADER COUNT N
+20 PRINT N
+30 PRINT N$(1), S(1)
+40 PRINT N$(2), S(2)
+`, ` 2
+Smith, J  90
+say "hi"  7.5
+`, nil},
+		{"NoHeader", map[string]string{"D.CSV": "1,2\n3,4\n5,6\n"}, `10 CSVREAD "D.CSV" INTO A(), B()
+20 PRINT A(1) + A(2) + A(3), B(3)
+`, " 9  6\n", nil},
+		{"Write", nil, `10 DIM N$(2), S(2)
+20 N$(1) = "A, B"
+30 N$(2) = "Q T"
+40 S(1) = 1.5
+50 S(2) = -2
+60 CSVWRITE "O.CSV" FROM N$(), S() HEADER
+`, "", map[string]string{"O.CSV": "N$,S\n\"A, B\",1.5\nQ T,-2\n"}},
+		{"RoundTrip", nil, `10 DIM A$(2), B%(2)
+20 A$(1) = "X,Y"
+30 A$(2) = ""
+40 B%(1) = 7
+50 B%(2) = -3
+60 CSVWRITE "R.CSV" FROM A$(), B%()
+70 CSVREAD "R.CSV" INTO C$(), D%() COUNT N
+80 PRINT N, C$(1), D%(1), C$(2), D%(2)
+`, " 2 X,Y  7  -3\n", nil},
+		{"NotANumber", map[string]string{"D.CSV": "1\n2\nX\n"}, `10 CSVREAD "D.CSV" INTO A()
+`, "runtime error at line 10: CSVREAD: line 3, column 1: \"X\" is not a number for A()\n", nil},
+		{"Overflow", map[string]string{"D.CSV": "1,40000\n"}, `10 DIM A(1), B%(1)
+20 A(1) = 5
+30 ON ERROR GOTO 100
+40 CSVREAD "D.CSV" INTO A(), B%()
+50 PRINT A(1), B%(1)
+60 END
+100 PRINT ERR, ERL
+110 RESUME NEXT
+`, " 6  40\n 5  0\n", nil},
+		{"ShortLine", map[string]string{"D.CSV": "1,2\n3\n"}, `10 CSVREAD "D.CSV" INTO A(), B()
+`, "runtime error at line 10: CSVREAD: line 2 has 1 fields, want 2\n", nil},
+		{"SizeMismatch", nil, `10 DIM A(2), B(3)
+20 CSVWRITE "O.CSV" FROM A(), B()
+`, "runtime error at line 20: CSVWRITE: A() and B() differ in size\n", nil},
+	}
+	for _, tt := range tests {
+		for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+			fsys := NewMemFS()
+			for name, data := range tt.files {
+				fsys.WriteFile(name, []byte(data))
+			}
+			got := runWith(parseSource(t, tt.src), WithFS(fsys), WithEngine(engine))
+			if got != tt.want {
+				t.Errorf("%s on engine %d: got\n%s\nwant\n%s", tt.name, engine, got, tt.want)
+			}
+			for name, want := range tt.wantFiles {
+				data, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					t.Errorf("%s on engine %d: %v", tt.name, engine, err)
+				} else if string(data) != want {
+					t.Errorf("%s on engine %d: %s is %q, want %q", tt.name, engine, name, data, want)
+				}
+			}
+		}
+	}
+}
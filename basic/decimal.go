@@ -0,0 +1,257 @@
+/**************************************************************/
+/*
+   decimal.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Rounding selects how decimal division results are rounded.
+type Rounding int
+
+const (
+	RoundHalfEven Rounding = iota // to nearest, ties to even (banker's rounding)
+	RoundHalfUp                   // to nearest, ties away from zero
+	RoundDown                     // toward zero
+	RoundUp                       // away from zero
+	RoundFloor                    // toward negative infinity
+	RoundCeiling                  // toward positive infinity
+)
+
+var roundingNames = []string{"half-even", "half-up", "down", "up", "floor", "ceiling"}
+
+func (r Rounding) String() string {
+	if int(r) < len(roundingNames) {
+		return roundingNames[r]
+	}
+	return fmt.Sprintf("Rounding(%d)", int(r))
+}
+
+func (r Rounding) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+func (r *Rounding) UnmarshalText(text []byte) error {
+	var err error
+	*r, err = ParseRounding(string(text))
+	return err
+}
+
+// ParseRounding returns the Rounding named name, e.g. "half-up".
+func ParseRounding(name string) (Rounding, error) {
+	for i, n := range roundingNames {
+		if strings.EqualFold(name, n) {
+			return Rounding(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rounding %q", name)
+}
+
+// DecimalOptions configures decimal mode. In decimal mode double numbers
+// (literals, INPUT and variables without a typed suffix) are exact
+// decimals: + - * and comparisons are exact and / is rounded to Scale
+// digits after the decimal point. Integer and single values keep their
+// binary arithmetic.
+type DecimalOptions struct {
+	Scale    int      `json:"scale"` // digits kept after the decimal point by division
+	Rounding Rounding `json:"rounding"`
+}
+
+// DefaultDecimal is used by OPTION DECIMAL when Interpreter.Decimal is
+// not set.
+var DefaultDecimal = DecimalOptions{Scale: 20, Rounding: RoundHalfEven}
+
+// option runs OPTION DECIMAL.
+func (it *Interpreter) option(s *OptionStmt) {
+	if it.dec == nil {
+		opts := DefaultDecimal
+		it.dec = &opts
+	}
+}
+
+// decimalValue returns r as a number, keeping a float64 approximation in
+// Num for the code that needs a plain number (IF, ERROR, \, ...).
+func decimalValue(r *big.Rat) Value {
+	f, _ := r.Float64()
+	return Value{Kind: ValNumber, Num: f, Dec: r}
+}
+
+// toRat returns the number v as a decimal. Binary values convert through
+// their shortest decimal form, so the literal 0.1 becomes exactly 0.1.
+func toRat(v Value) *big.Rat {
+	if v.Dec != nil {
+		return v.Dec
+	}
+	bits := 64
+	if v.Type == Single {
+		bits = 32
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(v.Num, 'g', -1, bits))
+	return r
+}
+
+// number returns the value of the numeric literal n.
+func (it *Interpreter) number(n float64) Value {
+	if it.dec != nil {
+		v := decimalValue(toRat(NumberValue(n)))
+		v.lit = true
+		return v
+	}
+	return literal(n)
+}
+
+// binary applies op to l and r, in decimal when decimal mode is on and
+// the result is a double.
+func (it *Interpreter) binary(op string, l, r Value) (Value, error) {
+	if it.dec != nil && (l.Dec != nil || r.Dec != nil) &&
+		l.Kind == ValNumber && r.Kind == ValNumber && op != "\\" && arithType(l, r) == Double {
+		v, err := decBinary(op, toRat(l), toRat(r), it.dec)
+		v.lit = l.lit && r.lit
+		return v, err
+	}
+	return evalBinary(op, l, r)
+}
+
+func decBinary(op string, l, r *big.Rat, opts *DecimalOptions) (Value, error) {
+	z := new(big.Rat)
+	switch op {
+	case "+":
+		return decimalValue(z.Add(l, r)), nil
+	case "-":
+		return decimalValue(z.Sub(l, r)), nil
+	case "*":
+		return decimalValue(z.Mul(l, r)), nil
+	case "/":
+		if r.Sign() == 0 {
+			return Value{}, newError(ErrDivisionByZero, "division by zero")
+		}
+		return decimalValue(roundRat(z.Quo(l, r), opts.Scale, opts.Rounding)), nil
+	}
+	var ok bool
+	switch c := l.Cmp(r); op {
+	case "=":
+		ok = c == 0
+	case "<>":
+		ok = c != 0
+	case "<":
+		ok = c < 0
+	case "<=":
+		ok = c <= 0
+	case ">":
+		ok = c > 0
+	case ">=":
+		ok = c >= 0
+	default:
+		return Value{}, newError(ErrSyntax, "unsupported operator %q", op)
+	}
+	if ok {
+		return NumberValue(1), nil
+	}
+	return NumberValue(0), nil
+}
+
+// roundRat rounds x to scale digits after the decimal point.
+func roundRat(x *big.Rat, scale int, mode Rounding) *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	num := new(big.Int).Mul(x.Num(), pow)
+	q, m := new(big.Int).QuoRem(num, x.Denom(), new(big.Int)) // q truncated toward zero
+	if m.Sign() != 0 {
+		neg := x.Sign() < 0
+		// compare 2|m| with the denominator to find ties
+		half := new(big.Int).Abs(m)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(x.Denom())
+		away := false
+		switch mode {
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundUp:
+			away = true
+		case RoundFloor:
+			away = neg
+		case RoundCeiling:
+			away = !neg
+		}
+		if away {
+			if neg {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+	return new(big.Rat).SetFrac(q, pow)
+}
+
+// formatDecimal formats the finite decimal r without trailing zeros.
+func formatDecimal(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	// the denominator is 2^a * 5^b and needs max(a, b) digits
+	d := new(big.Int).Set(r.Denom())
+	twos, fives := 0, 0
+	for d.Bit(0) == 0 {
+		d.Rsh(d, 1)
+		twos++
+	}
+	five, q, m := big.NewInt(5), new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(d, five, m)
+		if m.Sign() != 0 {
+			break
+		}
+		d.Set(q)
+		fives++
+	}
+	return r.FloatString(max(twos, fives))
+}
+
+// parseDecimal reads s in decimal notation: an optional sign, digits with
+// an optional point and an optional exponent. big.Rat alone would also
+// take fractions such as 1/3, which have no finite decimal form.
+func parseDecimal(s string) (*big.Rat, bool) {
+	i := 0
+	digits := func() int {
+		n := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+			n++
+		}
+		return n
+	}
+	sign := func() {
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+	}
+	sign()
+	n := digits()
+	if i < len(s) && s[i] == '.' {
+		i++
+		n += digits()
+	}
+	if n == 0 {
+		return nil, false
+	}
+	if i < len(s) && (s[i] == 'E' || s[i] == 'e') {
+		i++
+		sign()
+		if digits() == 0 {
+			return nil, false
+		}
+	}
+	if i != len(s) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
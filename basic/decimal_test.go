@@ -0,0 +1,130 @@
+/**************************************************************/
+/*
+   decimal_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		x    string
+		mode Rounding
+		want string // rounded to 0 digits
+	}{
+		{"5/2", RoundHalfEven, "2"},
+		{"7/2", RoundHalfEven, "4"},
+		{"-5/2", RoundHalfEven, "-2"},
+		{"5/2", RoundHalfUp, "3"},
+		{"-5/2", RoundHalfUp, "-3"},
+		{"12/5", RoundHalfUp, "2"},
+		{"13/5", RoundDown, "2"},
+		{"-13/5", RoundDown, "-2"},
+		{"11/5", RoundUp, "3"},
+		{"-11/5", RoundUp, "-3"},
+		{"-11/5", RoundFloor, "-3"},
+		{"11/5", RoundFloor, "2"},
+		{"-14/5", RoundCeiling, "-2"},
+		{"11/5", RoundCeiling, "3"},
+		{"2", RoundUp, "2"},
+	}
+	for _, tt := range tests {
+		x, _ := new(big.Rat).SetString(tt.x)
+		if got := roundRat(x, 0, tt.mode).RatString(); got != tt.want {
+			t.Errorf("roundRat(%s, 0, %s) = %s, want %s", tt.x, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string // "" if s is rejected
+	}{
+		{"1", "1"},
+		{"-0.5", "-0.5"},
+		{"+.25", "0.25"},
+		{"5.", "5"},
+		{"1.5E3", "1500"},
+		{"25e-2", "0.25"},
+		{"1/3", ""},
+		{"", ""},
+		{".", ""},
+		{"-", ""},
+		{"1E", ""},
+		{"1E+", ""},
+		{"0x10", ""},
+		{"1.2.3", ""},
+		{" 1", ""},
+		{"Inf", ""},
+	}
+	for _, tt := range tests {
+		r, ok := parseDecimal(tt.s)
+		got := ""
+		if ok {
+			got = formatDecimal(r)
+		}
+		if got != tt.want {
+			t.Errorf("parseDecimal(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  DecimalOptions
+		stdin string
+		files map[string]string
+		src   string
+		want  string
+	}{
+		{"Exact", DefaultDecimal, "", nil, `10 A = 0.1 + 0.2
+20 PRINT A, A = 0.3, 0.3 - 0.1
+30 PRINT 1.1 * 1.1, 10 / 4
+`, ` .3  1  .2
+ 1.21  2.5
+`},
+		{"Scale", DecimalOptions{Scale: 4, Rounding: RoundHalfUp}, "", nil, `10 PRINT 2 / 3, -2 / 3, 1 / 8
+`, " .6667 -.6667  .125\n"},
+		{"Rounding", DecimalOptions{Scale: 2, Rounding: RoundDown}, "", nil, `10 PRINT 2 / 3, -2 / 3, 1 / 8
+`, " .66 -.66  .12\n"},
+		{"Input", DefaultDecimal, "0.1\n", nil, `10 INPUT A
+20 PRINT A * 3, A * 3 = 0.3
+`, "?  .3  1\n"},
+		{"InputFraction", DefaultDecimal, "1/3\n", nil, `10 INPUT A
+`, "? runtime error at line 10: INPUT expects number\n"},
+		{"FileInput", DefaultDecimal, "", map[string]string{"IN": "0.1 1/3\n"}, `10 OPEN "IN" FOR INPUT AS #1
+20 INPUT #1, A
+30 PRINT A * 3
+40 INPUT #1, B
+`, " .3\nruntime error at line 40: INPUT # expects number for B\n"},
+		{"CSVFraction", DefaultDecimal, "", map[string]string{"D.CSV": "1/3\n"}, `10 CSVREAD "D.CSV" INTO A()
+`, "runtime error at line 10: CSVREAD: line 1, column 1: \"1/3\" is not a number for A()\n"},
+		{"Typed", DefaultDecimal, "", nil, `10 A% = 7
+20 B! = 0.1
+30 PRINT A% / 2, B! + 0.2, 0.1 + 0.2
+`, " 3.5  .3  .3\n"},
+	}
+	for _, tt := range tests {
+		for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+			fsys := NewMemFS()
+			for name, data := range tt.files {
+				fsys.WriteFile(name, []byte(data))
+			}
+			prog := parseSource(t, tt.src)
+			got := runWith(prog, WithDecimal(tt.opts), WithEngine(engine), WithStdin(strings.NewReader(tt.stdin)), WithFS(fsys))
+			if got != tt.want {
+				t.Errorf("%s on engine %d: got\n%s\nwant\n%s", tt.name, engine, got, tt.want)
+			}
+		}
+	}
+}
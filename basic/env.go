@@ -0,0 +1,203 @@
+/**************************************************************/
+/*
+   env.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Symbols numbers variables. A slot is never reused, so slots resolved
+// into the AST stay valid while lines are added or removed.
+type Symbols struct {
+	index map[string]int // name -> slot
+	names []string
+	isStr []bool
+	types []NumType
+
+	defs [26]varDef // type of names without suffix, by first letter (DEFINT etc.)
+}
+
+// varDef is the type given to a range of letters by a DEFtype statement.
+type varDef struct {
+	isStr bool
+	typ   NumType
+}
+
+func NewSymbols() *Symbols {
+	return &Symbols{index: map[string]int{}}
+}
+
+// Slot returns the slot of the upper-case name, allocating it if needed.
+func (s *Symbols) Slot(name string) int {
+	if slot, ok := s.index[name]; ok {
+		return slot
+	}
+	slot := len(s.names)
+	s.index[name] = slot
+	s.names = append(s.names, name)
+	isStr, typ := s.typeOf(name)
+	s.isStr = append(s.isStr, isStr)
+	s.types = append(s.types, typ)
+	return slot
+}
+
+// typeOf returns the type of name from its suffix or, without one, from
+// the DEFtype statements.
+func (s *Symbols) typeOf(name string) (bool, NumType) {
+	if isStr, typ, ok := suffixType(name); ok {
+		return isStr, typ
+	}
+	if c := name[0]; c >= 'A' && c <= 'Z' { // the lexer upper-cases names
+		d := s.defs[c-'A']
+		return d.isStr, d.typ
+	}
+	return false, Double
+}
+
+// setDefs changes the types of names without suffix.
+func (s *Symbols) setDefs(defs [26]varDef) {
+	s.defs = defs
+	for slot, name := range s.names {
+		s.isStr[slot], s.types[slot] = s.typeOf(name)
+	}
+}
+
+func (s *Symbols) Len() int { return len(s.names) }
+
+// Env stores variable values in slices indexed by slot. Get, Set and Has
+// take variable names for inspection and embedding; the interpreter uses
+// the slots resolved when the program is linked.
+type Env struct {
+	syms  *Symbols
+	num   []float64
+	dec   []*big.Rat // decimal values, nil for binary ones
+	str   []string
+	isSet []bool // the variable has been assigned
+	count int    // number of assigned variables
+
+	arrays map[string]*array // by name with suffix
+}
+
+func NewEnv() *Env {
+	return &Env{syms: NewSymbols()}
+}
+
+// bind switches e to the slots of syms, moving any assigned variables.
+func (e *Env) bind(syms *Symbols) {
+	if e.syms == syms {
+		e.grow()
+		return
+	}
+	old := *e
+	*e = Env{syms: syms, arrays: old.arrays}
+	e.grow()
+	for slot, ok := range old.isSet {
+		if ok {
+			e.store(syms.Slot(old.syms.names[slot]), old.load(slot))
+		}
+	}
+}
+
+// grow sizes the storage to the symbol table.
+func (e *Env) grow() {
+	for len(e.isSet) < e.syms.Len() {
+		e.num = append(e.num, 0)
+		e.dec = append(e.dec, nil)
+		e.str = append(e.str, "")
+		e.isSet = append(e.isSet, false)
+	}
+}
+
+func (e *Env) load(slot int) Value {
+	if e.syms.isStr[slot] {
+		return StringValue(e.str[slot]) // default value is ""
+	}
+	if d := e.dec[slot]; d != nil {
+		return Value{Kind: ValNumber, Num: e.num[slot], Type: e.syms.types[slot], Dec: d}
+	}
+	return Value{Kind: ValNumber, Num: e.num[slot], Type: e.syms.types[slot]} // default value is 0
+}
+
+// store assigns v to slot; the caller has checked its kind.
+func (e *Env) store(slot int, v Value) {
+	if e.syms.isStr[slot] {
+		e.str[slot] = v.Str
+	} else {
+		e.num[slot] = v.Num
+		e.dec[slot] = v.Dec
+	}
+	if !e.isSet[slot] {
+		e.isSet[slot] = true
+		e.count++
+	}
+}
+
+func (e *Env) Get(name string) Value {
+	name = strings.ToUpper(name)
+	slot, ok := e.syms.index[name]
+	if !ok || slot >= len(e.isSet) {
+		isStr, typ := e.syms.typeOf(name)
+		if isStr {
+			return StringValue("")
+		}
+		return Value{Kind: ValNumber, Type: typ}
+	}
+	return e.load(slot)
+}
+
+func (e *Env) Has(name string) bool {
+	slot, ok := e.syms.index[strings.ToUpper(name)]
+	return ok && slot < len(e.isSet) && e.isSet[slot]
+}
+
+func (e *Env) Len() int {
+	return e.count
+}
+
+// Names returns the names of the assigned variables in sorted order.
+func (e *Env) Names() []string {
+	names := make([]string, 0, e.count)
+	for slot, ok := range e.isSet {
+		if ok {
+			names = append(names, e.syms.names[slot])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Env) Set(name string, v Value) error {
+	name = strings.ToUpper(name)
+	slot := e.syms.Slot(name)
+	if err := checkKind(name, e.syms.isStr[slot], v); err != nil {
+		return err
+	}
+	if v.Kind == ValNumber {
+		var err error
+		if v, err = toType(v, e.syms.types[slot]); err != nil {
+			return err
+		}
+	}
+	e.grow()
+	e.store(slot, v)
+	return nil
+}
+
+// checkKind reports a type mismatch when v does not fit the variable name.
+func checkKind(name string, isStr bool, v Value) error {
+	if isStr && v.Kind != ValString {
+		return newError(ErrTypeMismatch, "type mismatch: %s is string variable", name)
+	}
+	if !isStr && v.Kind != ValNumber {
+		return newError(ErrTypeMismatch, "type mismatch: %s is numeric variable", name)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/**************************************************************/
+/*
+   env_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import "testing"
+
+// variablesProg is a loop over a handful of variables, resolved to slots
+// when the program is linked.
+const variablesProg = `10 A = 0
+20 B = 1
+30 C = 2
+40 I = 0
+50 A = A + I
+60 B = A - B
+70 C = C + B - A
+80 I = I + 1
+90 IF I < 10000 THEN 50
+100 PRINT A, B, C
+`
+
+func BenchmarkVariables(b *testing.B) {
+	b.Run("Tree", func(b *testing.B) { benchmarkEngine(b, EngineTree, variablesProg) })
+	b.Run("VM", func(b *testing.B) { benchmarkEngine(b, EngineVM, variablesProg) })
+}
+
+// BenchmarkEnvName reads and writes a variable by name, as embedders do.
+func BenchmarkEnvName(b *testing.B) {
+	e := NewEnv()
+	e.Set("A", NumberValue(0))
+	for b.Loop() {
+		if err := e.Set("A", NumberValue(e.Get("A").Num+1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEnvSlot reads and writes a variable by the slot the linker
+// resolves, as the engines do.
+func BenchmarkEnvSlot(b *testing.B) {
+	e := NewEnv()
+	e.Set("A", NumberValue(0))
+	slot := e.syms.Slot("A")
+	for b.Loop() {
+		e.store(slot, NumberValue(e.load(slot).Num+1))
+	}
+}
@@ -0,0 +1,118 @@
+/**************************************************************/
+/*
+   errors.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// classic Microsoft BASIC error codes
+const (
+	ErrSyntax              = 2
+	ErrReturnWithoutGosub  = 3
+	ErrOutOfData           = 4
+	ErrIllegalFunction     = 5
+	ErrOverflow            = 6
+	ErrOutOfMemory         = 7
+	ErrUndefinedLine       = 8
+	ErrSubscriptRange      = 9
+	ErrDuplicateDefinition = 10
+	ErrDivisionByZero      = 11
+	ErrTypeMismatch        = 13
+	ErrStringTooLong       = 15
+	ErrUndefinedFunction   = 18
+	ErrNoResume            = 19
+	ErrResumeWithoutError  = 20
+	ErrFieldOverflow       = 50
+	ErrBadFileNumber       = 52
+	ErrFileNotFound        = 53
+	ErrBadFileMode         = 54
+	ErrFileAlreadyOpen     = 55
+	ErrInputPastEnd        = 62
+	ErrBadRecordNumber     = 63
+	ErrBadFileName         = 64
+	ErrPathAccess          = 75
+)
+
+var errorMessages = map[int]string{
+	ErrSyntax:              "syntax error",
+	ErrReturnWithoutGosub:  "RETURN without GOSUB",
+	ErrOutOfData:           "out of DATA",
+	ErrIllegalFunction:     "illegal function call",
+	ErrOverflow:            "overflow",
+	ErrOutOfMemory:         "out of memory",
+	ErrUndefinedLine:       "undefined line number",
+	ErrSubscriptRange:      "subscript out of range",
+	ErrDuplicateDefinition: "duplicate definition",
+	ErrDivisionByZero:      "division by zero",
+	ErrTypeMismatch:        "type mismatch",
+	ErrStringTooLong:       "string too long",
+	ErrUndefinedFunction:   "undefined user function",
+	ErrNoResume:            "no RESUME",
+	ErrResumeWithoutError:  "RESUME without error",
+	ErrFieldOverflow:       "FIELD overflow",
+	ErrBadFileNumber:       "bad file number",
+	ErrFileNotFound:        "file not found",
+	ErrBadFileMode:         "bad file mode",
+	ErrFileAlreadyOpen:     "file already open",
+	ErrInputPastEnd:        "input past end",
+	ErrBadRecordNumber:     "bad record number",
+	ErrBadFileName:         "bad file name",
+	ErrPathAccess:          "path/file access error",
+}
+
+// errorMessage returns the standard message for a BASIC error code.
+func errorMessage(code int) string {
+	if msg, ok := errorMessages[code]; ok {
+		return msg
+	}
+	return "unprintable error"
+}
+
+// BasicError is a runtime error raised while executing a program.
+// Code is 0 when the cause is not a BASIC error (a resource limit or an
+// I/O failure); the cause is then available through Unwrap.
+type BasicError struct {
+	Code int
+	Line int    // line number, 0 if unknown
+	Stmt string // statement being executed
+	Msg  string
+	Err  error
+}
+
+func (e *BasicError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("runtime error at line %d: %s", e.Line, e.Msg)
+	}
+	return "runtime error: " + e.Msg
+}
+
+func (e *BasicError) Unwrap() error { return e.Err }
+
+func newError(code int, format string, a ...any) *BasicError {
+	return &BasicError{Code: code, Msg: fmt.Sprintf(format, a...)}
+}
+
+// trappable reports whether an ON ERROR handler may catch e. Resource
+// limits are never trappable so a handler cannot keep a program alive.
+func (e *BasicError) trappable() bool { return e.Code != 0 }
+
+// asBasicError returns err as a *BasicError located at lineNo.
+func asBasicError(err error, lineNo int, stmt Stmt) *BasicError {
+	var be *BasicError
+	if !errors.As(err, &be) {
+		be = &BasicError{Msg: err.Error(), Err: err}
+	}
+	if be.Line == 0 {
+		be.Line = lineNo
+		be.Stmt = stmt.String()
+	}
+	return be
+}
@@ -0,0 +1,94 @@
+/**************************************************************/
+/*
+   extension.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"sync"
+)
+
+// StmtParseFunc parses an extension statement into s. The parser is on
+// the keyword when it is called and must be left on the last token of
+// the statement. Operands to be evaluated go in s.Args; anything else the
+// statement needs can be kept in s.Data. Errors are reported with
+// p.Errorf.
+type StmtParseFunc func(p *Parser, s *ExtStmt)
+
+// StmtExecFunc runs an extension statement. args holds s.Args evaluated.
+type StmtExecFunc func(it *Interpreter, s *ExtStmt, args []Value) error
+
+type stmtExt struct {
+	keyword string
+	parse   StmtParseFunc
+	exec    StmtExecFunc
+}
+
+var (
+	extMu    sync.RWMutex
+	extStmts = map[string]*stmtExt{}
+)
+
+// RegisterStatement adds a statement starting with keyword. A nil parse
+// reads a comma separated list of expressions, as in SEND "queue", X$.
+// Statements must be registered before the programs using them are
+// parsed, typically from an init function.
+func RegisterStatement(keyword string, parse StmtParseFunc, exec StmtExecFunc) error {
+	if !validFuncName(keyword) {
+		return fmt.Errorf("invalid keyword %q", keyword)
+	}
+	if _, ok := builtinFuncs[keyword]; ok {
+		return fmt.Errorf("%s is a built-in function", keyword)
+	}
+	if exec == nil {
+		return fmt.Errorf("%s: missing exec function", keyword)
+	}
+	if parse == nil {
+		parse = parseExtArgs
+	}
+	extMu.Lock()
+	defer extMu.Unlock()
+	extStmts[keyword] = &stmtExt{keyword: keyword, parse: parse, exec: exec}
+	return nil
+}
+
+func lookupStmt(keyword string) (*stmtExt, bool) {
+	extMu.RLock()
+	defer extMu.RUnlock()
+	ext, ok := extStmts[keyword]
+	return ext, ok
+}
+
+func parseExtArgs(p *Parser, s *ExtStmt) {
+	if p.Peek().Type == EOF {
+		return
+	}
+	p.Next()
+	s.Args = p.ParseExprList()
+}
+
+func (p *Parser) parseExtStmt(ext *stmtExt) Stmt {
+	s := &ExtStmt{Keyword: ext.keyword, ext: ext}
+	n := len(p.errors)
+	ext.parse(p, s)
+	if len(p.errors) > n {
+		return nil
+	}
+	return s
+}
+
+func (it *Interpreter) execExt(s *ExtStmt) error {
+	args, err := it.evalArgs(s.Args)
+	if err != nil {
+		return err
+	}
+	if err := s.ext.exec(it, s, args); err != nil {
+		return hostError(s.Keyword, err)
+	}
+	return nil
+}
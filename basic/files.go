@@ -0,0 +1,379 @@
+/**************************************************************/
+/*
+   files.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WriteFS is the file system used by OPEN: an fs.FS that can also create
+// and change files. DirFS and MemFS implement it.
+type WriteFS interface {
+	fs.FS
+	// OpenFile opens name, a path valid for fs.FS, with the os.OpenFile
+	// flags.
+	OpenFile(name string, flag int, perm fs.FileMode) (WriteFile, error)
+}
+
+// WriteFile is a file opened by WriteFS.OpenFile.
+type WriteFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+// DirFS returns the file system of the operating system rooted at dir.
+func DirFS(dir string) WriteFS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+func (d dirFS) OpenFile(name string, flag int, perm fs.FileMode) (WriteFile, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.OpenFile(filepath.Join(d.dir, filepath.FromSlash(name)), flag, perm)
+}
+
+type fileMode int
+
+const (
+	modeInput fileMode = iota
+	modeOutput
+	modeAppend
+	modeRandom
+)
+
+var fileModeNames = []string{"INPUT", "OUTPUT", "APPEND", "RANDOM"}
+
+func (m fileMode) String() string { return fileModeNames[m] }
+
+// openFile is a file opened by OPEN.
+type openFile struct {
+	mode fileMode
+	c    io.Closer
+	r    *bufio.Reader // INPUT
+	w    io.Writer     // OUTPUT and APPEND
+
+	// RANDOM
+	rw     WriteFile
+	buf    []byte  // record buffer
+	rec    int64   // number of the last record read or written
+	eof    bool    // the last GET read past the end
+	fields []field // variables mapped onto buf by FIELD
+}
+
+// open runs OPEN.
+func (it *Interpreter) open(s *OpenStmt) error {
+	name, err := it.evalExpr(s.Name)
+	if err != nil {
+		return err
+	}
+	if name.Kind != ValString {
+		return newError(ErrTypeMismatch, "OPEN requires a file name")
+	}
+	n, err := it.fileNumber(s.File)
+	if err != nil {
+		return err
+	}
+	if _, ok := it.files[n]; ok {
+		return newError(ErrFileAlreadyOpen, "file #%d already open", n)
+	}
+	if it.FS == nil {
+		return newError(ErrPathAccess, "no file system")
+	}
+
+	f := &openFile{mode: s.Mode}
+	switch s.Mode {
+	case modeRandom:
+		reclen := defaultRecordLen
+		if s.Len != nil {
+			v, err := it.evalExpr(s.Len)
+			if err != nil {
+				return err
+			}
+			if v.Kind != ValNumber || v.Num < 1 || v.Num > 32767 {
+				return newError(ErrIllegalFunction, "bad record length %s", v)
+			}
+			reclen = int(v.Num)
+		}
+		rw, err := it.FS.OpenFile(name.Str, os.O_RDWR|os.O_CREATE, 0o666)
+		if err != nil {
+			return fileError(err)
+		}
+		f.c, f.rw, f.buf = rw, rw, make([]byte, reclen)
+	case modeInput:
+		r, err := it.FS.Open(name.Str)
+		if err != nil {
+			return fileError(err)
+		}
+		f.c, f.r = r, bufio.NewReader(r)
+	default:
+		flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if s.Mode == modeAppend {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		w, err := it.FS.OpenFile(name.Str, flag, 0o666)
+		if err != nil {
+			return fileError(err)
+		}
+		f.c, f.w = w, w
+	}
+	if it.files == nil {
+		it.files = map[int]*openFile{}
+	}
+	it.files[n] = f
+	return nil
+}
+
+// fileError returns the BASIC error for a failed OPEN.
+func fileError(err error) error {
+	code := ErrPathAccess
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = ErrFileNotFound
+	case errors.Is(err, fs.ErrInvalid):
+		code = ErrBadFileName
+	}
+	be := newError(code, "%v", err)
+	be.Err = err
+	return be
+}
+
+// fileNumber evaluates the file number e.
+func (it *Interpreter) fileNumber(e Expr) (int, error) {
+	v, err := it.evalExpr(e)
+	if err != nil {
+		return 0, err
+	}
+	return toFileNumber(v)
+}
+
+func toFileNumber(v Value) (int, error) {
+	if v.Kind != ValNumber {
+		return 0, newError(ErrTypeMismatch, "file number must be numeric")
+	}
+	n := int(v.Num)
+	if n < 1 || n > 255 || float64(n) != v.Num {
+		return 0, newError(ErrBadFileNumber, "bad file number %s", v)
+	}
+	return n, nil
+}
+
+// file returns the open file e in one of modes.
+func (it *Interpreter) file(e Expr, modes ...fileMode) (*openFile, error) {
+	n, err := it.fileNumber(e)
+	if err != nil {
+		return nil, err
+	}
+	return it.lookupFile(n, modes...)
+}
+
+func (it *Interpreter) lookupFile(n int, modes ...fileMode) (*openFile, error) {
+	f, ok := it.files[n]
+	if !ok {
+		return nil, newError(ErrBadFileNumber, "file #%d not open", n)
+	}
+	for _, m := range modes {
+		if f.mode == m {
+			return f, nil
+		}
+	}
+	return nil, newError(ErrBadFileMode, "file #%d is open for %s", n, f.mode)
+}
+
+// close runs CLOSE.
+func (it *Interpreter) close(s *CloseStmt) error {
+	if len(s.Files) == 0 {
+		return it.closeFiles()
+	}
+	for _, e := range s.Files {
+		n, err := it.fileNumber(e)
+		if err != nil {
+			return err
+		}
+		if f, ok := it.files[n]; ok {
+			delete(it.files, n)
+			if err := f.c.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// closeFiles closes all open files, as at the end of a run.
+func (it *Interpreter) closeFiles() error {
+	var errs []error
+	for n, f := range it.files {
+		delete(it.files, n)
+		errs = append(errs, f.c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// fileInput runs INPUT # and LINE INPUT #.
+func (it *Interpreter) fileInput(s *FileInputStmt) error {
+	f, err := it.file(s.File, modeInput)
+	if err != nil {
+		return err
+	}
+	for i, slot := range s.Slots {
+		isStr := it.Env.syms.isStr[slot]
+		var v Value
+		switch {
+		case s.Line:
+			if !isStr {
+				return newError(ErrTypeMismatch, "LINE INPUT requires a string variable")
+			}
+			line, err := f.r.ReadString('\n')
+			if line == "" && err != nil {
+				return inputError(err)
+			}
+			v = StringValue(strings.TrimRight(line, "\r\n"))
+		case isStr:
+			item, err := f.readItem(false)
+			if err != nil {
+				return err
+			}
+			v = StringValue(item)
+		default:
+			item, err := f.readItem(true)
+			if err != nil {
+				return err
+			}
+			if v, err = it.parseNumber(item); err != nil {
+				return newError(ErrTypeMismatch, "INPUT # expects number for %s", s.Names[i])
+			}
+		}
+		if err := it.setSlot(slot, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inputError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return newError(ErrInputPastEnd, "input past end")
+	}
+	return err
+}
+
+// parseNumber converts the text of an INPUT # item.
+func (it *Interpreter) parseNumber(s string) (Value, error) {
+	if s == "" {
+		return NumberValue(0), nil
+	}
+	if it.dec != nil {
+		r, ok := parseDecimal(s)
+		if !ok {
+			return Value{}, strconv.ErrSyntax
+		}
+		return decimalValue(r), nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return NumberValue(n), err
+}
+
+// readItem reads the next INPUT # item: a quoted string, or text up to a
+// comma or the end of the line, or also up to a space for a number.
+// Leading spaces and line breaks are skipped.
+func (f *openFile) readItem(num bool) (string, error) {
+	c, err := f.skip(" \t\r\n")
+	if err != nil {
+		return "", inputError(err)
+	}
+	var b strings.Builder
+	if c == '"' && !num {
+		for {
+			c, err := f.r.ReadByte()
+			if err != nil || c == '\n' {
+				return b.String(), nil
+			}
+			if c == '"' {
+				return b.String(), f.endItem()
+			}
+			b.WriteByte(c)
+		}
+	}
+	f.r.UnreadByte()
+	for {
+		c, err := f.r.ReadByte()
+		if err != nil || c == ',' || c == '\n' {
+			break
+		}
+		if num && (c == ' ' || c == '\t') {
+			return b.String(), f.endItem()
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimRight(b.String(), " \t\r"), nil
+}
+
+// endItem skips the spaces after an item and the comma ending it.
+func (f *openFile) endItem() error {
+	c, err := f.skip(" \t\r")
+	if err == nil && c != ',' && c != '\n' {
+		f.r.UnreadByte()
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// skip reads past the bytes in set and returns the byte after them.
+func (f *openFile) skip(set string) (byte, error) {
+	for {
+		c, err := f.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if strings.IndexByte(set, c) < 0 {
+			return c, nil
+		}
+	}
+}
+
+// eof returns EOF(n): true when file n has no more input, or for a
+// RANDOM file when the last GET read past the end.
+func (it *Interpreter) eof(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return Value{}, newError(ErrIllegalFunction, "EOF takes 1 argument")
+	}
+	n, err := toFileNumber(args[0])
+	if err != nil {
+		return Value{}, err
+	}
+	f, err := it.lookupFile(n, modeInput, modeRandom)
+	if err != nil {
+		return Value{}, err
+	}
+	if f.mode == modeRandom {
+		if f.eof {
+			return NumberValue(1), nil
+		}
+		return NumberValue(0), nil
+	}
+	if _, err := f.r.Peek(1); err != nil {
+		return NumberValue(1), nil
+	}
+	return NumberValue(0), nil
+}
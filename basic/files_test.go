@@ -0,0 +1,97 @@
+/**************************************************************/
+/*
+   files_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string // before the run
+		src       string
+		want      string
+		wantFiles map[string]string // after the run
+	}{
+		{"WriteRead", nil, `10 OPEN "T.TXT" FOR OUTPUT AS #1
+20 PRINT #1, "HELLO"
+30 PRINT #1, 42, -1.5
+40 CLOSE #1
+50 OPEN "T.TXT" FOR INPUT AS #2
+60 INPUT #2, A$, B
+70 INPUT #2, C
+80 PRINT A$, B, C, EOF(2)
+`, "HELLO  42 -1.5  1\n", map[string]string{"T.TXT": `HELLO
+ 42 -1.5
+`}},
+		{"Append", map[string]string{"LOG": "A\n"}, `10 OPEN "LOG" FOR APPEND AS #1
+20 PRINT #1, "B"
+`, "", map[string]string{"LOG": "A\nB\n"}},
+		{"LineInput", map[string]string{"IN": "\"X\", 1\nLAST LINE"}, `10 OPEN "IN" FOR INPUT AS #1
+20 LINE INPUT #1, A$
+30 PRINT A$
+40 IF EOF(1) THEN 80
+50 LINE INPUT #1, A$
+60 PRINT A$
+70 GOTO 40
+80 PRINT "DONE"
+`, `"X", 1
+LAST LINE
+DONE
+`, nil},
+		{"QuotedItems", map[string]string{"IN": "\"A, B\",  \"C\"\n7 8\n"}, `10 OPEN "IN" FOR INPUT AS #1
+20 INPUT #1, A$, B$, X, Y
+30 PRINT A$, B$, X + Y
+`, "A, B C  15\n", nil},
+		{"Errors", nil, `10 ON ERROR GOTO 100
+20 OPEN "NONE" FOR INPUT AS #1
+30 OPEN "A" FOR OUTPUT AS #1
+40 OPEN "B" FOR OUTPUT AS #1
+50 PRINT #3, 1
+60 INPUT #1, X
+70 OPEN "" FOR INPUT AS #2
+80 END
+100 PRINT ERR, ERL
+110 RESUME NEXT
+`, ` 53  20
+ 55  40
+ 52  50
+ 54  60
+ 64  70
+`, nil},
+		{"ReadPastEnd", map[string]string{"IN": "1\n"}, `10 OPEN "IN" FOR INPUT AS #1
+20 INPUT #1, A, B
+`, "runtime error at line 20: input past end\n", nil},
+		{"ClosedAtEnd", nil, `10 OPEN "OUT" FOR OUTPUT AS #1
+20 PRINT #1, "KEPT"
+`, "", map[string]string{"OUT": "KEPT\n"}},
+	}
+	for _, tt := range tests {
+		for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+			fsys := NewMemFS()
+			for name, data := range tt.files {
+				fsys.WriteFile(name, []byte(data))
+			}
+			got := runWith(parseSource(t, tt.src), WithFS(fsys), WithEngine(engine))
+			if got != tt.want {
+				t.Errorf("%s on engine %d: got\n%s\nwant\n%s", tt.name, engine, got, tt.want)
+			}
+			for name, want := range tt.wantFiles {
+				data, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					t.Errorf("%s on engine %d: %v", tt.name, engine, err)
+				} else if string(data) != want {
+					t.Errorf("%s on engine %d: %s is %q, want %q", tt.name, engine, name, data, want)
+				}
+			}
+		}
+	}
+}
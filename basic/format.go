@@ -0,0 +1,110 @@
+/**************************************************************/
+/*
+   format.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat selects how PRINT and STR$ format numbers.
+type NumberFormat int
+
+const (
+	FormatClassic NumberFormat = iota // like classic BASIC: " 1.5", "-.3", " 1E+21"
+	FormatGo                          // Value.String: Go's shortest form, "1.5", "-0.3", "1e+21"
+)
+
+// formatNumber formats the number v for PRINT and STR$.
+func (it *Interpreter) formatNumber(v Value) string {
+	if it.NumberFormat == FormatGo {
+		return v.String()
+	}
+	return classicNumber(v)
+}
+
+// classicNumber formats v like classic BASIC: a leading space or minus
+// sign, no zero before the decimal point, and up to 9 (single) or 16
+// (double) significant digits. E notation is used when the digits do not
+// fit before or after the point.
+func classicNumber(v Value) string {
+	if v.Dec != nil {
+		s := formatDecimal(v.Dec)
+		if neg := strings.HasPrefix(s, "-"); neg {
+			return "-" + strings.TrimPrefix(s[1:], "0")
+		}
+		if s == "0" {
+			return " 0"
+		}
+		return " " + strings.TrimPrefix(s, "0")
+	}
+	switch v.Type {
+	case Integer:
+		if v.Num < 0 {
+			return strconv.Itoa(int(v.Num))
+		}
+		return " " + strconv.Itoa(int(v.Num))
+	case Single:
+		return classicFloat(v.Num, 9, 32)
+	}
+	return classicFloat(v.Num, 16, 64)
+}
+
+// classicFloat formats n with at most digits significant digits; bits is
+// the precision of n.
+func classicFloat(n float64, digits, bits int) string {
+	if math.IsInf(n, 0) || math.IsNaN(n) {
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	}
+	sign := " "
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	if n == 0 {
+		return " 0"
+	}
+
+	// d.ddddde±x, the shortest form unless it has too many digits
+	s := strconv.FormatFloat(n, 'e', -1, bits)
+	if len(s) > digits+5 { // d . digits-1 e±xx
+		s = strconv.FormatFloat(n, 'e', digits-1, bits)
+	}
+	mant, exp, _ := strings.Cut(s, "e")
+	e, _ := strconv.Atoi(exp)
+	d := strings.TrimRight(strings.Replace(mant, ".", "", 1), "0")
+
+	switch {
+	case e >= digits || (e < 0 && -e-1+len(d) > digits):
+		var b strings.Builder
+		b.WriteString(sign)
+		b.WriteByte(d[0])
+		if len(d) > 1 {
+			b.WriteString("." + d[1:])
+		}
+		b.WriteByte('E')
+		if e < 0 {
+			b.WriteByte('-')
+			e = -e
+		} else {
+			b.WriteByte('+')
+		}
+		if e < 10 {
+			b.WriteByte('0')
+		}
+		b.WriteString(strconv.Itoa(e))
+		return b.String()
+	case e < 0:
+		return sign + "." + strings.Repeat("0", -e-1) + d
+	case len(d) <= e+1:
+		return sign + d + strings.Repeat("0", e+1-len(d))
+	}
+	return sign + d[:e+1] + "." + d[e+1:]
+}
@@ -6,7 +6,7 @@
 */
 /**************************************************************/
 
-package main
+package basic
 
 import (
 	"strings"
@@ -26,11 +26,12 @@ const (
 	STRING TokenType = "STRING"
 
 	// operators
-	ASSIGN TokenType = "="
-	PLUS   TokenType = "+"
-	MINUS  TokenType = "-"
-	ASTER  TokenType = "*"
-	SLASH  TokenType = "/"
+	ASSIGN    TokenType = "="
+	PLUS      TokenType = "+"
+	MINUS     TokenType = "-"
+	ASTER     TokenType = "*"
+	SLASH     TokenType = "/"
+	BACKSLASH TokenType = "\\" // integer division
 
 	EQ  TokenType = "=" // same lexeme as ASSIGN; parser decides context
 	NEQ TokenType = "<>"
@@ -43,16 +44,43 @@ const (
 	LPAREN TokenType = "("
 	RPAREN TokenType = ")"
 	COMMA  TokenType = ","
+	SEMI   TokenType = ";"
+	HASH   TokenType = "#" // file number
 
 	// keywords
-	REM   TokenType = "REM"
-	LET   TokenType = "LET"
-	PRINT TokenType = "PRINT"
-	INPUT TokenType = "INPUT"
-	IF    TokenType = "IF"
-	THEN  TokenType = "THEN"
-	GOTO  TokenType = "GOTO"
-	END   TokenType = "END"
+	REM       TokenType = "REM"
+	LET       TokenType = "LET"
+	PRINT     TokenType = "PRINT"
+	INPUT     TokenType = "INPUT"
+	IF        TokenType = "IF"
+	THEN      TokenType = "THEN"
+	GOTO      TokenType = "GOTO"
+	GOSUB     TokenType = "GOSUB"
+	RETURN    TokenType = "RETURN"
+	END       TokenType = "END"
+	ON        TokenType = "ON"
+	ERROR     TokenType = "ERROR"
+	RESUME    TokenType = "RESUME"
+	NEXT      TokenType = "NEXT"
+	RANDOMIZE TokenType = "RANDOMIZE"
+	DEFINT    TokenType = "DEFINT"
+	DEFSNG    TokenType = "DEFSNG"
+	DEFDBL    TokenType = "DEFDBL"
+	DEFSTR    TokenType = "DEFSTR"
+	OPTION    TokenType = "OPTION"
+	USING     TokenType = "USING"
+	OPEN      TokenType = "OPEN"
+	CLOSE     TokenType = "CLOSE"
+	LINE      TokenType = "LINE"
+	FIELD     TokenType = "FIELD"
+	GET       TokenType = "GET"
+	PUT       TokenType = "PUT"
+	LSET      TokenType = "LSET"
+	RSET      TokenType = "RSET"
+	DIM       TokenType = "DIM"
+	CSVREAD   TokenType = "CSVREAD"
+	CSVWRITE  TokenType = "CSVWRITE"
+	SNAPSHOT  TokenType = "SNAPSHOT"
 
 	// REPL commands
 	RUN  TokenType = "RUN"
@@ -63,20 +91,46 @@ const (
 type Token struct {
 	Type    TokenType
 	Literal string
+	Col     int // column of the first character, from 1
 }
 
 var keywords = map[string]TokenType{
-	"REM":   REM,
-	"LET":   LET,
-	"PRINT": PRINT,
-	"INPUT": INPUT,
-	"IF":    IF,
-	"THEN":  THEN,
-	"GOTO":  GOTO,
-	"END":   END,
-	"RUN":   RUN,
-	"LIST":  LIST,
-	"NEW":   NEW,
+	"REM":       REM,
+	"LET":       LET,
+	"PRINT":     PRINT,
+	"INPUT":     INPUT,
+	"IF":        IF,
+	"THEN":      THEN,
+	"GOTO":      GOTO,
+	"GOSUB":     GOSUB,
+	"RETURN":    RETURN,
+	"END":       END,
+	"ON":        ON,
+	"ERROR":     ERROR,
+	"RESUME":    RESUME,
+	"NEXT":      NEXT,
+	"RANDOMIZE": RANDOMIZE,
+	"DEFINT":    DEFINT,
+	"DEFSNG":    DEFSNG,
+	"DEFDBL":    DEFDBL,
+	"DEFSTR":    DEFSTR,
+	"OPTION":    OPTION,
+	"USING":     USING,
+	"OPEN":      OPEN,
+	"CLOSE":     CLOSE,
+	"LINE":      LINE,
+	"FIELD":     FIELD,
+	"GET":       GET,
+	"PUT":       PUT,
+	"LSET":      LSET,
+	"RSET":      RSET,
+	"DIM":       DIM,
+	"CSVREAD":   CSVREAD,
+	"CSVWRITE":  CSVWRITE,
+	"SNAPSHOT":  SNAPSHOT,
+	"RUN":       RUN,
+	"LIST":      LIST,
+	"NEW":       NEW,
 }
 
 func LookupIdent(s string) TokenType {
@@ -125,7 +179,13 @@ func (l *Lexer) skipWhitespace() {
 
 func (l *Lexer) NextToken() Token {
 	l.skipWhitespace()
+	col := l.position + 1
+	tok := l.next()
+	tok.Col = col
+	return tok
+}
 
+func (l *Lexer) next() Token {
 	switch l.ch {
 	case 0:
 		return Token{Type: EOF, Literal: ""}
@@ -145,6 +205,10 @@ func (l *Lexer) NextToken() Token {
 		tok := Token{Type: SLASH, Literal: "/"}
 		l.readChar()
 		return tok
+	case '\\':
+		tok := Token{Type: BACKSLASH, Literal: "\\"}
+		l.readChar()
+		return tok
 	case '(':
 		tok := Token{Type: LPAREN, Literal: "("}
 		l.readChar()
@@ -157,6 +221,14 @@ func (l *Lexer) NextToken() Token {
 		tok := Token{Type: COMMA, Literal: ","}
 		l.readChar()
 		return tok
+	case ';':
+		tok := Token{Type: SEMI, Literal: ";"}
+		l.readChar()
+		return tok
+	case '#':
+		tok := Token{Type: HASH, Literal: "#"}
+		l.readChar()
+		return tok
 	case '=':
 		tok := Token{Type: ASSIGN, Literal: "="}
 		l.readChar()
@@ -211,7 +283,11 @@ func (l *Lexer) readIdentifier() string {
 	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
-	if l.ch == '$' {
+	if _, ok := keywords[strings.ToUpper(l.input[start:l.position])]; ok {
+		return l.input[start:l.position] // PRINT#1 is PRINT #1
+	}
+	switch l.ch {
+	case '$', '%', '!', '#': // type suffix
 		l.readChar()
 	}
 	return l.input[start:l.position]
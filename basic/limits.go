@@ -0,0 +1,87 @@
+/**************************************************************/
+/*
+   limits.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Limits bounds the resources one Run may use. A zero field means unlimited.
+type Limits struct {
+	MaxDuration    time.Duration // wall-clock time of the whole run
+	MaxOutputBytes int           // bytes written to Out
+	MaxStringLen   int           // length of a string stored in a variable
+	MaxVariables   int           // number of distinct variables
+	MaxGosubDepth  int           // nested GOSUB calls
+}
+
+type TimeLimitError struct {
+	Limit time.Duration
+}
+
+func (e *TimeLimitError) Error() string {
+	return fmt.Sprintf("time limit exceeded (%v)", e.Limit)
+}
+
+type OutputLimitError struct {
+	Limit int
+}
+
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("output limit exceeded (%d bytes)", e.Limit)
+}
+
+type StringLimitError struct {
+	Limit int
+	Len   int
+}
+
+func (e *StringLimitError) Error() string {
+	return fmt.Sprintf("string too long (%d > %d)", e.Len, e.Limit)
+}
+
+type VariableLimitError struct {
+	Limit int
+}
+
+func (e *VariableLimitError) Error() string {
+	return fmt.Sprintf("too many variables (limit %d)", e.Limit)
+}
+
+type GosubDepthError struct {
+	Limit int
+}
+
+func (e *GosubDepthError) Error() string {
+	return fmt.Sprintf("GOSUB nesting too deep (limit %d)", e.Limit)
+}
+
+// limitWriter passes writes through until max bytes have been written.
+type limitWriter struct {
+	w   io.Writer
+	n   int
+	max int
+}
+
+func (lw *limitWriter) Write(b []byte) (int, error) {
+	if lw.n+len(b) > lw.max {
+		rest := lw.max - lw.n
+		n, err := lw.w.Write(b[:rest])
+		lw.n += n
+		if err != nil {
+			return n, err
+		}
+		return n, &OutputLimitError{Limit: lw.max}
+	}
+	n, err := lw.w.Write(b)
+	lw.n += n
+	return n, err
+}
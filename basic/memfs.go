@@ -0,0 +1,158 @@
+/**************************************************************/
+/*
+   memfs.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+// MemFS is a WriteFS kept in memory, for tests and sandboxes. It has no
+// directories: a name is a key like "data/in.txt".
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*memData
+}
+
+type memData struct {
+	data    []byte
+	modTime time.Time
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string]*memData{}}
+}
+
+// WriteFile sets the contents of name.
+func (m *MemFS) WriteFile(name string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &memData{data: append([]byte(nil), data...), modTime: time.Now()}
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	f, err := m.openFile(name, os.O_RDONLY)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (m *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (WriteFile, error) {
+	f, err := m.openFile(name, flag)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (m *MemFS) openFile(name string, flag int) (*memFile, error) {
+	const op = "open"
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.files[name]
+	switch {
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	case ok && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrExist}
+	case !ok:
+		d = &memData{modTime: time.Now()}
+		m.files[name] = d
+	case flag&os.O_TRUNC != 0:
+		d.data = d.data[:0]
+		d.modTime = time.Now()
+	}
+	return &memFile{fs: m, name: name, d: d, flag: flag}, nil
+}
+
+// memFile is an open MemFS file.
+type memFile struct {
+	fs   *MemFS
+	name string
+	d    *memData
+	flag int
+	off  int64
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == os.O_WRONLY {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrPermission}
+	}
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.off >= int64(len(f.d.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.d.data[f.off:])
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+	}
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.flag&os.O_APPEND != 0 {
+		f.off = int64(len(f.d.data))
+	}
+	if end := f.off + int64(len(p)); end > int64(len(f.d.data)) {
+		f.d.data = append(f.d.data, make([]byte, end-int64(len(f.d.data)))...)
+	}
+	copy(f.d.data[f.off:], p)
+	f.off += int64(len(p))
+	f.d.modTime = time.Now()
+	return len(p), nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += int64(len(f.d.data))
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *memFile) Close() error { return nil }
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	return memInfo{name: f.name, size: int64(len(f.d.data)), modTime: f.d.modTime}, nil
+}
+
+type memInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (i memInfo) Name() string       { return path.Base(i.name) }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return 0o666 }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return false }
+func (i memInfo) Sys() any           { return nil }
@@ -0,0 +1,186 @@
+/**************************************************************/
+/*
+   optimize.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import "math/big"
+
+// Optimize returns stmt with constant subexpressions folded, unary plus
+// and double negation removed and IF 0 THEN statements dropped. stmt
+// itself is not modified. An expression that would fail, such as 1 / 0,
+// is left as it is so the error is still raised when it runs.
+func Optimize(stmt Stmt) Stmt {
+	switch s := stmt.(type) {
+	case *LetStmt:
+		opt := &LetStmt{Name: s.Name, Slot: s.Slot, Expr: optimizeExpr(s.Expr)}
+		for _, e := range s.Index {
+			opt.Index = append(opt.Index, optimizeExpr(e))
+		}
+		return opt
+	case *PrintStmt:
+		exprs := make([]Expr, len(s.Exprs))
+		for i, e := range s.Exprs {
+			exprs[i] = optimizeExpr(e)
+		}
+		opt := &PrintStmt{File: s.File, Exprs: exprs}
+		if s.Using != nil {
+			opt.Using = optimizeExpr(s.Using)
+		}
+		return opt
+	case *IfStmt:
+		cond := optimizeExpr(s.Cond)
+		if n, ok := cond.(*NumberLit); ok && n.Value == 0 {
+			return &RemStmt{}
+		}
+		opt := *s
+		opt.Cond = cond
+		if !s.HasLine {
+			opt.ThenStmt = Optimize(s.ThenStmt)
+		}
+		return &opt
+	case *ErrorStmt:
+		return &ErrorStmt{Code: optimizeExpr(s.Code)}
+	case *RandomizeStmt:
+		return &RandomizeStmt{Seed: optimizeExpr(s.Seed)}
+	case *ExtStmt:
+		opt := *s
+		opt.Args = make([]Expr, len(s.Args))
+		for i, e := range s.Args {
+			opt.Args[i] = optimizeExpr(e)
+		}
+		return &opt
+	}
+	return stmt
+}
+
+func optimizeExpr(e Expr) Expr {
+	switch x := e.(type) {
+	case *UnaryExpr:
+		rhs := optimizeExpr(x.Rhs)
+		if n, ok := rhs.(*NumberLit); ok {
+			if x.Op == "-" {
+				return &NumberLit{Value: -n.Value}
+			}
+			return n
+		}
+		if !isNumeric(rhs) {
+			// keep the operator so a string operand still fails
+			return &UnaryExpr{Op: x.Op, Rhs: rhs}
+		}
+		if x.Op == "+" {
+			return rhs
+		}
+		if inner, ok := rhs.(*UnaryExpr); ok && inner.Op == "-" && isNumeric(inner.Rhs) {
+			return inner.Rhs
+		}
+		return &UnaryExpr{Op: x.Op, Rhs: rhs}
+
+	case *BinaryExpr:
+		lhs := optimizeExpr(x.Lhs)
+		rhs := optimizeExpr(x.Rhs)
+		if l, ok := literalValue(lhs); ok {
+			if r, ok := literalValue(rhs); ok {
+				if v, err := evalBinary(x.Op, l, r); err == nil && v.lit && decimalSafe(x.Op, l, r, v) {
+					return &NumberLit{Value: v.Num}
+				}
+			}
+		}
+		return &BinaryExpr{Op: x.Op, Lhs: lhs, Rhs: rhs}
+
+	case *FuncExpr:
+		args := make([]Expr, len(x.Args))
+		for i, a := range x.Args {
+			args[i] = optimizeExpr(a)
+		}
+		return &FuncExpr{Name: x.Name, Args: args}
+	}
+	return e
+}
+
+// decimalSafe reports whether v, the result of l op r, is also the result
+// in decimal mode, which may be turned on after the program is optimized.
+func decimalSafe(op string, l, r, v Value) bool {
+	if l.Kind != ValNumber || r.Kind != ValNumber {
+		return true
+	}
+	lr, rr := toRat(l), toRat(r)
+	switch op {
+	case "+", "-", "*":
+		d, _ := decBinary(op, lr, rr, &DefaultDecimal)
+		return d.Dec.Cmp(toRat(v)) == 0
+	case "/":
+		// the rounding of decimal division is configurable
+		if rr.Sign() == 0 {
+			return false
+		}
+		q := new(big.Rat).Quo(lr, rr)
+		return q.IsInt() && q.Cmp(toRat(v)) == 0
+	}
+	d, _ := decBinary(op, lr, rr, &DefaultDecimal)
+	return d.Num == v.Num
+}
+
+func literalValue(e Expr) (Value, bool) {
+	switch x := e.(type) {
+	case *NumberLit:
+		return literal(x.Value), true
+	case *StringLit:
+		return StringValue(x.Value), true
+	}
+	return Value{}, false
+}
+
+// isNumeric reports whether e always yields a double when it succeeds.
+// Variables must have been resolved.
+func isNumeric(e Expr) bool {
+	switch x := e.(type) {
+	case *NumberLit:
+		return true
+	case *UnaryExpr:
+		return isNumeric(x.Rhs)
+	case *BinaryExpr:
+		switch x.Op {
+		case "+", "-", "*", "/":
+			// the type of the wider operand, see arithType; a string
+			// operand fails
+			l, r := isConst(x.Lhs), isConst(x.Rhs)
+			switch {
+			case l && r:
+				return true
+			case l:
+				return isNumeric(x.Rhs)
+			case r:
+				return isNumeric(x.Lhs)
+			}
+			return isNumeric(x.Lhs) || isNumeric(x.Rhs)
+		case "\\":
+			return false
+		}
+		return true // comparison
+	case *VarRef:
+		return !x.IsStr && x.Type == Double
+	}
+	return false
+}
+
+// isConst reports whether e yields an untyped literal: a number or
+// arithmetic on numbers.
+func isConst(e Expr) bool {
+	switch x := e.(type) {
+	case *NumberLit:
+		return true
+	case *UnaryExpr:
+		return isConst(x.Rhs)
+	case *BinaryExpr:
+		switch x.Op {
+		case "+", "-", "*", "/":
+			return isConst(x.Lhs) && isConst(x.Rhs)
+		}
+	}
+	return false
+}
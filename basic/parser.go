@@ -0,0 +1,934 @@
+/**************************************************************/
+/*
+   parser.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type precedence int
+
+const (
+	_ precedence = iota
+	LOWEST
+	COMPARE // = <> < <= > >=
+	SUM     // + -
+	INTDIV  // \\
+	PRODUCT // * /
+	PREFIX  // unary + -
+)
+
+// precedence map
+var precedences = map[TokenType]precedence{
+	ASSIGN:    COMPARE,
+	NEQ:       COMPARE,
+	LT:        COMPARE,
+	LTE:       COMPARE,
+	GT:        COMPARE,
+	GTE:       COMPARE,
+	PLUS:      SUM,
+	MINUS:     SUM,
+	ASTER:     PRODUCT,
+	SLASH:     PRODUCT,
+	BACKSLASH: INTDIV,
+}
+
+type Parser struct {
+	l       *Lexer
+	curTok  Token // current token
+	peekTok Token // next token (for lookahead)
+	errors  []string
+}
+
+func NewParser(src string) *Parser {
+	p := &Parser{l: NewLexer(src)}
+	p.nextToken()
+	p.nextToken()
+	return p
+}
+
+func (p *Parser) nextToken() {
+	p.curTok = p.peekTok
+	p.peekTok = p.l.NextToken()
+}
+
+func (p *Parser) Errors() []string { return p.errors }
+
+func (p *Parser) addErr(format string, a ...any) {
+	p.errors = append(p.errors, fmt.Sprintf(format, a...))
+}
+
+// Cur returns the current token.
+func (p *Parser) Cur() Token { return p.curTok }
+
+// Peek returns the token after the current one.
+func (p *Parser) Peek() Token { return p.peekTok }
+
+// Next advances to the next token.
+func (p *Parser) Next() { p.nextToken() }
+
+// Expect advances to the next token if it has type t and reports an
+// error otherwise.
+func (p *Parser) Expect(t TokenType) bool {
+	if p.peekTok.Type != t {
+		p.addErr("expected %s, got %s", t, p.peekTok.Type)
+		return false
+	}
+	p.nextToken()
+	return true
+}
+
+// Errorf reports a syntax error.
+func (p *Parser) Errorf(format string, a ...any) { p.addErr(format, a...) }
+
+// ParseExpr parses the expression starting at the current token and
+// leaves the parser on its last token. It returns nil after an error.
+func (p *Parser) ParseExpr() Expr { return p.parseExpr(LOWEST) }
+
+// ParseExprList parses comma separated expressions starting at the
+// current token. It returns nil after an error.
+func (p *Parser) ParseExprList() []Expr {
+	var exprs []Expr
+	for {
+		e := p.parseExpr(LOWEST)
+		if e == nil {
+			return nil
+		}
+		exprs = append(exprs, e)
+		if p.peekTok.Type != COMMA {
+			return exprs
+		}
+		p.nextToken() // comma
+		p.nextToken() // expr
+	}
+}
+
+func (p *Parser) ParseStatement() Stmt {
+	col := p.curTok.Col
+	s := p.parseStatement()
+	setCol(s, col)
+	return s
+}
+
+func (p *Parser) parseStatement() Stmt {
+	switch p.curTok.Type {
+	case REM:
+		return &RemStmt{}
+	case LET:
+		return p.parseLetStmt(true)
+	case IDENT:
+		if ext, ok := lookupStmt(p.curTok.Literal); ok {
+			return p.parseExtStmt(ext)
+		}
+		if p.peekTok.Type == ASSIGN || p.peekTok.Type == LPAREN {
+			return p.parseLetStmt(false)
+		}
+		p.addErr("unexpected identifier %q", p.curTok.Literal)
+		return nil
+	case PRINT:
+		return p.parsePrintStmt()
+	case INPUT:
+		return p.parseInputStmt()
+	case LINE:
+		return p.parseLineInputStmt()
+	case OPEN:
+		return p.parseOpenStmt()
+	case CLOSE:
+		return p.parseCloseStmt()
+	case FIELD:
+		return p.parseFieldStmt()
+	case GET, PUT:
+		return p.parseRecordStmt()
+	case LSET, RSET:
+		return p.parseLsetStmt()
+	case DIM:
+		return p.parseDimStmt()
+	case CSVREAD, CSVWRITE:
+		return p.parseCSVStmt()
+	case SNAPSHOT:
+		return p.parseSnapshotStmt(false)
+	case IF:
+		return p.parseIfStmt()
+	case GOTO:
+		return p.parseGotoStmt()
+	case GOSUB:
+		return p.parseGosubStmt()
+	case RETURN:
+		return &ReturnStmt{}
+	case ON:
+		return p.parseOnErrorStmt()
+	case RESUME:
+		return p.parseResumeStmt()
+	case ERROR:
+		return p.parseErrorStmt()
+	case RANDOMIZE:
+		return p.parseRandomizeStmt()
+	case DEFINT, DEFSNG, DEFDBL, DEFSTR:
+		return p.parseDefStmt()
+	case OPTION:
+		return p.parseOptionStmt()
+	case END:
+		return &EndStmt{}
+	default:
+		p.addErr("unexpected token %s", p.curTok.Type)
+		return nil
+	}
+}
+
+func (p *Parser) parseLetStmt(hasLET bool) Stmt {
+	var name string
+	if hasLET {
+		p.nextToken() // move to IDENT
+		if p.curTok.Type != IDENT {
+			p.addErr("LET requires identifier")
+			return nil
+		}
+		name = p.curTok.Literal
+	} else {
+		if p.curTok.Type != IDENT {
+			p.addErr("assignment requires identifier")
+			return nil
+		}
+		name = p.curTok.Literal
+	}
+
+	var index []Expr
+	if p.peekTok.Type == LPAREN {
+		p.nextToken()
+		if index = p.parseSubscripts(); index == nil {
+			return nil
+		}
+	}
+	if p.peekTok.Type != ASSIGN {
+		p.addErr("expected '=' after identifier")
+		return nil
+	}
+	p.nextToken() // '='
+	p.nextToken() // expr start
+
+	expr := p.parseExpr(LOWEST)
+	if expr == nil {
+		return nil
+	}
+	return &LetStmt{Name: name, Index: index, Expr: expr}
+}
+
+// parseSubscripts parses (expr, ...) from the current '('.
+func (p *Parser) parseSubscripts() []Expr {
+	var exprs []Expr
+	for {
+		p.nextToken()
+		e := p.parseExpr(LOWEST)
+		if e == nil {
+			return nil
+		}
+		exprs = append(exprs, e)
+		if p.peekTok.Type != COMMA {
+			break
+		}
+		p.nextToken()
+	}
+	if p.peekTok.Type != RPAREN {
+		p.addErr("expected ')'")
+		return nil
+	}
+	p.nextToken()
+	return exprs
+}
+
+// parseDimStmt parses DIM name(bounds), ...
+func (p *Parser) parseDimStmt() Stmt {
+	s := &DimStmt{}
+	for {
+		p.nextToken()
+		if p.curTok.Type != IDENT || p.peekTok.Type != LPAREN {
+			p.addErr("DIM requires name(bounds)")
+			return nil
+		}
+		name := p.curTok.Literal
+		p.nextToken()
+		bounds := p.parseSubscripts()
+		if bounds == nil {
+			return nil
+		}
+		s.Names = append(s.Names, name)
+		s.Bounds = append(s.Bounds, bounds)
+		if p.peekTok.Type != COMMA {
+			return s
+		}
+		p.nextToken()
+	}
+}
+
+// parseCSVStmt parses CSVREAD file INTO name(), ... [HEADER] [COUNT var]
+// and CSVWRITE file FROM name(), ... [HEADER].
+func (p *Parser) parseCSVStmt() Stmt {
+	s := &CSVStmt{Write: p.curTok.Type == CSVWRITE}
+	dir := "INTO"
+	if s.Write {
+		dir = "FROM"
+	}
+	p.nextToken()
+	if s.Name = p.parseExpr(LOWEST); s.Name == nil {
+		return nil
+	}
+	p.nextToken()
+	if p.curTok.Type != IDENT || p.curTok.Literal != dir {
+		p.addErr("%s requires %s", s.keyword(), dir)
+		return nil
+	}
+	for {
+		p.nextToken()
+		if p.curTok.Type != IDENT || p.peekTok.Type != LPAREN {
+			p.addErr("%s requires array name()", s.keyword())
+			return nil
+		}
+		s.Arrays = append(s.Arrays, p.curTok.Literal)
+		p.nextToken()
+		if p.peekTok.Type != RPAREN {
+			p.addErr("expected ')'")
+			return nil
+		}
+		p.nextToken()
+		if p.peekTok.Type != COMMA {
+			break
+		}
+		p.nextToken()
+	}
+	if p.peekTok.Type == IDENT && p.peekTok.Literal == "HEADER" {
+		p.nextToken()
+		s.Header = true
+	}
+	if !s.Write && p.peekTok.Type == IDENT && p.peekTok.Literal == "COUNT" {
+		p.nextToken()
+		p.nextToken()
+		if p.curTok.Type != IDENT {
+			p.addErr("COUNT requires identifier")
+			return nil
+		}
+		s.Count = p.curTok.Literal
+	}
+	return s
+}
+
+func (p *Parser) parsePrintStmt() Stmt {
+	var file Expr
+	if p.peekTok.Type == HASH {
+		p.nextToken()
+		if file = p.parseFileNumber(); file == nil {
+			return nil
+		}
+		if p.peekTok.Type == EOF {
+			return &PrintStmt{File: file}
+		}
+		if p.peekTok.Type != COMMA {
+			p.addErr("PRINT # requires , after the file number")
+			return nil
+		}
+		p.nextToken()
+	}
+	if p.peekTok.Type == EOF {
+		return &PrintStmt{File: file}
+	}
+	if p.peekTok.Type == USING {
+		s, ok := p.parsePrintUsing().(*PrintStmt)
+		if !ok {
+			return nil
+		}
+		s.File = file
+		return s
+	}
+	p.nextToken() // move to first expr
+	exprs := []Expr{}
+	first := p.parseExpr(LOWEST)
+	if first == nil {
+		return nil
+	}
+	exprs = append(exprs, first)
+
+	for p.peekTok.Type == COMMA {
+		p.nextToken() // comma
+		p.nextToken() // expr
+		e := p.parseExpr(LOWEST)
+		if e == nil {
+			return nil
+		}
+		exprs = append(exprs, e)
+	}
+	return &PrintStmt{File: file, Exprs: exprs}
+}
+
+// parsePrintUsing parses PRINT USING format; expr, ... where the
+// expressions are separated by commas or semicolons.
+func (p *Parser) parsePrintUsing() Stmt {
+	p.nextToken() // USING
+	p.nextToken()
+	format := p.parseExpr(LOWEST)
+	if format == nil {
+		return nil
+	}
+	if p.peekTok.Type != SEMI {
+		p.addErr("PRINT USING requires ; after the format")
+		return nil
+	}
+	p.nextToken()
+	exprs := []Expr{}
+	for p.peekTok.Type != EOF {
+		p.nextToken()
+		e := p.parseExpr(LOWEST)
+		if e == nil {
+			return nil
+		}
+		exprs = append(exprs, e)
+		if p.peekTok.Type != COMMA && p.peekTok.Type != SEMI {
+			break
+		}
+		p.nextToken()
+	}
+	return &PrintStmt{Using: format, Exprs: exprs}
+}
+
+func (p *Parser) parseInputStmt() Stmt {
+	if p.peekTok.Type == HASH {
+		return p.parseFileInput(false)
+	}
+	p.nextToken()
+	if p.curTok.Type != IDENT {
+		p.addErr("INPUT requires identifier")
+		return nil
+	}
+	return &InputStmt{Name: p.curTok.Literal}
+}
+
+// parseFileNumber parses the file number after #, the current token.
+func (p *Parser) parseFileNumber() Expr {
+	p.nextToken()
+	return p.parseExpr(LOWEST)
+}
+
+// parseLineInputStmt parses LINE INPUT #file, var$.
+func (p *Parser) parseLineInputStmt() Stmt {
+	p.nextToken()
+	if p.curTok.Type != INPUT || p.peekTok.Type != HASH {
+		p.addErr("LINE requires INPUT #")
+		return nil
+	}
+	s, ok := p.parseFileInput(true).(*FileInputStmt)
+	if !ok {
+		return nil
+	}
+	if len(s.Names) != 1 {
+		p.addErr("LINE INPUT # takes one variable")
+		return nil
+	}
+	return s
+}
+
+// parseFileInput parses the rest of INPUT #file, var, ... with # as the
+// next token.
+func (p *Parser) parseFileInput(line bool) Stmt {
+	p.nextToken()
+	file := p.parseFileNumber()
+	if file == nil {
+		return nil
+	}
+	s := &FileInputStmt{File: file, Line: line}
+	for p.peekTok.Type == COMMA {
+		p.nextToken()
+		p.nextToken()
+		if p.curTok.Type != IDENT {
+			p.addErr("INPUT # requires identifier")
+			return nil
+		}
+		s.Names = append(s.Names, p.curTok.Literal)
+	}
+	if len(s.Names) == 0 {
+		p.addErr("INPUT # requires , and a variable")
+		return nil
+	}
+	return s
+}
+
+// parseOpenStmt parses OPEN name [FOR INPUT|OUTPUT|APPEND|RANDOM] AS
+// [#]file [LEN = reclen]. Without FOR the file is RANDOM.
+func (p *Parser) parseOpenStmt() Stmt {
+	p.nextToken()
+	name := p.parseExpr(LOWEST)
+	if name == nil {
+		return nil
+	}
+	p.nextToken()
+	s := &OpenStmt{Name: name, Mode: modeRandom}
+	if p.curTok.Type == IDENT && p.curTok.Literal == "FOR" {
+		p.nextToken()
+		switch {
+		case p.curTok.Type == INPUT:
+			s.Mode = modeInput
+		case p.curTok.Literal == "OUTPUT":
+			s.Mode = modeOutput
+		case p.curTok.Literal == "APPEND":
+			s.Mode = modeAppend
+		case p.curTok.Literal == "RANDOM":
+			s.Mode = modeRandom
+		default:
+			p.addErr("unknown OPEN mode %q", p.curTok.Literal)
+			return nil
+		}
+		p.nextToken()
+	}
+	if p.curTok.Type != IDENT || p.curTok.Literal != "AS" {
+		p.addErr("OPEN requires AS")
+		return nil
+	}
+	if p.peekTok.Type == HASH {
+		p.nextToken()
+	}
+	if s.File = p.parseFileNumber(); s.File == nil {
+		return nil
+	}
+	if p.peekTok.Type == IDENT && p.peekTok.Literal == "LEN" {
+		if s.Mode != modeRandom {
+			p.addErr("LEN requires a RANDOM file")
+			return nil
+		}
+		p.nextToken()
+		if p.peekTok.Type != ASSIGN {
+			p.addErr("LEN requires =")
+			return nil
+		}
+		p.nextToken()
+		p.nextToken()
+		if s.Len = p.parseExpr(LOWEST); s.Len == nil {
+			return nil
+		}
+	}
+	return s
+}
+
+// parseFieldStmt parses FIELD [#]file, width AS var$, ...
+func (p *Parser) parseFieldStmt() Stmt {
+	if p.peekTok.Type == HASH {
+		p.nextToken()
+	}
+	s := &FieldStmt{}
+	if s.File = p.parseFileNumber(); s.File == nil {
+		return nil
+	}
+	for p.peekTok.Type == COMMA {
+		p.nextToken()
+		p.nextToken()
+		width := p.parseExpr(LOWEST)
+		if width == nil {
+			return nil
+		}
+		p.nextToken()
+		if p.curTok.Type != IDENT || p.curTok.Literal != "AS" {
+			p.addErr("FIELD requires AS")
+			return nil
+		}
+		p.nextToken()
+		if p.curTok.Type != IDENT {
+			p.addErr("FIELD requires identifier")
+			return nil
+		}
+		s.Widths = append(s.Widths, width)
+		s.Names = append(s.Names, p.curTok.Literal)
+	}
+	if len(s.Names) == 0 {
+		p.addErr("FIELD requires width AS variable")
+		return nil
+	}
+	return s
+}
+
+// parseRecordStmt parses GET|PUT [#]file[, record].
+func (p *Parser) parseRecordStmt() Stmt {
+	s := &RecordStmt{Put: p.curTok.Type == PUT}
+	if p.peekTok.Type == HASH {
+		p.nextToken()
+	}
+	if s.File = p.parseFileNumber(); s.File == nil {
+		return nil
+	}
+	if p.peekTok.Type == COMMA {
+		p.nextToken()
+		p.nextToken()
+		if s.Record = p.parseExpr(LOWEST); s.Record == nil {
+			return nil
+		}
+	}
+	return s
+}
+
+// parseLsetStmt parses LSET|RSET var$ = expr.
+func (p *Parser) parseLsetStmt() Stmt {
+	s := &LsetStmt{Right: p.curTok.Type == RSET}
+	p.nextToken()
+	if p.curTok.Type != IDENT {
+		p.addErr("%s requires identifier", s.keyword())
+		return nil
+	}
+	s.Name = p.curTok.Literal
+	if p.peekTok.Type != ASSIGN {
+		p.addErr("expected '=' after identifier")
+		return nil
+	}
+	p.nextToken()
+	p.nextToken()
+	if s.Expr = p.parseExpr(LOWEST); s.Expr == nil {
+		return nil
+	}
+	return s
+}
+
+// parseCloseStmt parses CLOSE [[#]file, ...].
+func (p *Parser) parseCloseStmt() Stmt {
+	s := &CloseStmt{}
+	if p.peekTok.Type == EOF {
+		return s
+	}
+	for {
+		if p.peekTok.Type == HASH {
+			p.nextToken()
+		}
+		file := p.parseFileNumber()
+		if file == nil {
+			return nil
+		}
+		s.Files = append(s.Files, file)
+		if p.peekTok.Type != COMMA {
+			return s
+		}
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseIfStmt() Stmt {
+	p.nextToken()
+	cond := p.parseExpr(LOWEST)
+	if cond == nil {
+		return nil
+	}
+
+	if p.peekTok.Type != THEN {
+		p.addErr("THEN requires statement or line number")
+		return nil
+	}
+	p.nextToken() // THEN
+	p.nextToken() // token after THEN
+
+	// THEN linenumber
+	if p.curTok.Type == NUMBER && p.peekTok.Type == EOF {
+		n, err := parseIntStrict(p.curTok.Literal)
+		if err != nil {
+			p.addErr("invalid line number after THEN: %v", err)
+			return nil
+		}
+		return &IfStmt{Cond: cond, ThenLine: n, HasLine: true}
+	}
+
+	// THEN statement
+	thenStmt := p.ParseStatement()
+	if thenStmt == nil {
+		return nil
+	}
+	return &IfStmt{Cond: cond, ThenStmt: thenStmt}
+}
+
+func (p *Parser) parseGotoStmt() Stmt {
+	p.nextToken()
+	if p.curTok.Type != NUMBER {
+		p.addErr("GOTO requires line number")
+		return nil
+	}
+	n, err := parseIntStrict(p.curTok.Literal)
+	if err != nil {
+		p.addErr("invalid GOTO line number: %v", err)
+		return nil
+	}
+	return &GotoStmt{Line: n}
+}
+
+func (p *Parser) parseGosubStmt() Stmt {
+	p.nextToken()
+	if p.curTok.Type != NUMBER {
+		p.addErr("GOSUB requires line number")
+		return nil
+	}
+	n, err := parseIntStrict(p.curTok.Literal)
+	if err != nil {
+		p.addErr("invalid GOSUB line number: %v", err)
+		return nil
+	}
+	return &GosubStmt{Line: n}
+}
+
+func (p *Parser) parseOnErrorStmt() Stmt {
+	if p.peekTok.Type != ERROR {
+		p.addErr("ON requires ERROR GOTO")
+		return nil
+	}
+	p.nextToken()
+	if p.peekTok.Type != GOTO {
+		p.addErr("ON ERROR requires GOTO")
+		return nil
+	}
+	p.nextToken()
+	p.nextToken()
+	if p.curTok.Type != NUMBER {
+		p.addErr("ON ERROR GOTO requires line number")
+		return nil
+	}
+	n, err := parseIntStrict(p.curTok.Literal)
+	if err != nil {
+		p.addErr("invalid ON ERROR GOTO line number: %v", err)
+		return nil
+	}
+	return &OnErrorStmt{Line: n}
+}
+
+func (p *Parser) parseResumeStmt() Stmt {
+	switch p.peekTok.Type {
+	case EOF:
+		return &ResumeStmt{}
+	case NEXT:
+		p.nextToken()
+		return &ResumeStmt{Next: true}
+	case SNAPSHOT:
+		p.nextToken()
+		return p.parseSnapshotStmt(true)
+	case NUMBER:
+		p.nextToken()
+		n, err := parseIntStrict(p.curTok.Literal)
+		if err != nil {
+			p.addErr("invalid RESUME line number: %v", err)
+			return nil
+		}
+		return &ResumeStmt{Line: n}
+	default:
+		p.addErr("RESUME requires NEXT or line number")
+		return nil
+	}
+}
+
+// parseSnapshotStmt parses SNAPSHOT file and, after RESUME, SNAPSHOT file.
+func (p *Parser) parseSnapshotStmt(resume bool) Stmt {
+	p.nextToken()
+	name := p.parseExpr(LOWEST)
+	if name == nil {
+		return nil
+	}
+	return &SnapshotStmt{Resume: resume, Name: name}
+}
+
+func (p *Parser) parseErrorStmt() Stmt {
+	p.nextToken()
+	code := p.parseExpr(LOWEST)
+	if code == nil {
+		return nil
+	}
+	return &ErrorStmt{Code: code}
+}
+
+func (p *Parser) parseRandomizeStmt() Stmt {
+	if p.peekTok.Type == EOF {
+		return &RandomizeStmt{Seed: &FuncExpr{Name: "TIMER"}}
+	}
+	p.nextToken()
+	seed := p.parseExpr(LOWEST)
+	if seed == nil {
+		return nil
+	}
+	return &RandomizeStmt{Seed: seed}
+}
+
+// parseDefStmt parses DEFtype letter[-letter], ...
+func (p *Parser) parseDefStmt() Stmt {
+	s := &DefStmt{}
+	switch p.curTok.Type {
+	case DEFINT:
+		s.Type = Integer
+	case DEFSNG:
+		s.Type = Single
+	case DEFSTR:
+		s.Str = true
+	}
+	for {
+		p.nextToken()
+		from, ok := p.letter()
+		if !ok {
+			return nil
+		}
+		to := from
+		if p.peekTok.Type == MINUS {
+			p.nextToken()
+			p.nextToken()
+			if to, ok = p.letter(); !ok {
+				return nil
+			}
+			if to < from {
+				p.addErr("invalid letter range %c-%c", from, to)
+				return nil
+			}
+		}
+		s.Ranges = append(s.Ranges, LetterRange{From: from, To: to})
+		if p.peekTok.Type != COMMA {
+			return s
+		}
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseOptionStmt() Stmt {
+	p.nextToken()
+	if p.curTok.Type != IDENT || p.curTok.Literal != "DECIMAL" {
+		p.addErr("unknown option %q", p.curTok.Literal)
+		return nil
+	}
+	return &OptionStmt{Name: p.curTok.Literal}
+}
+
+// letter returns the current token as a single letter.
+func (p *Parser) letter() (byte, bool) {
+	lit := p.curTok.Literal
+	if p.curTok.Type != IDENT || len(lit) != 1 || lit[0] < 'A' || lit[0] > 'Z' {
+		p.addErr("expected letter, got %q", lit)
+		return 0, false
+	}
+	return lit[0], true
+}
+
+func (p *Parser) parseExpr(pr precedence) Expr {
+	left := p.parsePrefix()
+	if left == nil {
+		return nil
+	}
+
+	for p.peekTok.Type != EOF && pr < p.peekPrecedence() {
+		switch p.peekTok.Type {
+		case PLUS, MINUS, ASTER, SLASH, BACKSLASH, ASSIGN, NEQ, LT, LTE, GT, GTE:
+			p.nextToken()
+			left = p.parseInfix(left)
+			if left == nil {
+				return nil
+			}
+		default:
+			return left
+		}
+	}
+	return left
+}
+
+func (p *Parser) parsePrefix() Expr {
+	col := p.curTok.Col
+	e := p.prefix()
+	setCol(e, col)
+	return e
+}
+
+func (p *Parser) prefix() Expr {
+	switch p.curTok.Type {
+	case NUMBER:
+		v, err := strconv.ParseFloat(p.curTok.Literal, 64)
+		if err != nil {
+			p.addErr("invalid number %q", p.curTok.Literal)
+			return nil
+		}
+		return &NumberLit{Value: v}
+	case STRING:
+		return &StringLit{Value: p.curTok.Literal}
+	case IDENT:
+		if p.peekTok.Type == LPAREN {
+			return p.parseCall()
+		}
+		if _, ok := builtinFuncs[p.curTok.Literal]; ok {
+			return &FuncExpr{Name: p.curTok.Literal}
+		}
+		return &VarRef{Name: p.curTok.Literal}
+	case PLUS, MINUS:
+		op := p.curTok.Literal
+		p.nextToken()
+		rhs := p.parseExpr(PREFIX)
+		if rhs == nil {
+			return nil
+		}
+		return &UnaryExpr{Op: op, Rhs: rhs}
+	case LPAREN:
+		p.nextToken()
+		e := p.parseExpr(LOWEST)
+		if e == nil {
+			return nil
+		}
+		if p.peekTok.Type != RPAREN {
+			p.addErr("expected ')'")
+			return nil
+		}
+		p.nextToken() // consume ')'
+		return e
+	default:
+		p.addErr("unexpected token in expression: %s", p.curTok.Type)
+		return nil
+	}
+}
+
+// parseCall parses NAME(arg, ...). Names other than built-in functions
+// are resolved when the call runs, so host functions can be registered
+// after parsing.
+func (p *Parser) parseCall() Expr {
+	call := &FuncExpr{Name: p.curTok.Literal}
+	p.nextToken() // '('
+	if p.peekTok.Type == RPAREN {
+		p.nextToken()
+		return call
+	}
+	for {
+		p.nextToken()
+		arg := p.parseExpr(LOWEST)
+		if arg == nil {
+			return nil
+		}
+		call.Args = append(call.Args, arg)
+		if p.peekTok.Type != COMMA {
+			break
+		}
+		p.nextToken()
+	}
+	if p.peekTok.Type != RPAREN {
+		p.addErr("expected ')'")
+		return nil
+	}
+	p.nextToken() // consume ')'
+	return call
+}
+
+func (p *Parser) parseInfix(left Expr) Expr {
+	opTok := p.curTok
+	prec := p.curPrecedence()
+	p.nextToken()
+	right := p.parseExpr(prec)
+	if right == nil {
+		return nil
+	}
+	return &BinaryExpr{Pos: *left.(positioned).pos(), Op: opTok.Literal, Lhs: left, Rhs: right}
+}
+
+func (p *Parser) peekPrecedence() precedence {
+	if pr, ok := precedences[p.peekTok.Type]; ok {
+		return pr
+	}
+	return LOWEST
+}
+
+func (p *Parser) curPrecedence() precedence {
+	if pr, ok := precedences[p.curTok.Type]; ok {
+		return pr
+	}
+	return LOWEST
+}
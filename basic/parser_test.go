@@ -0,0 +1,37 @@
+/**************************************************************/
+/*
+   parser_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import "testing"
+
+func TestParseStatements(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string // String of the parsed statement
+	}{
+		{`PRINT#1, "HI"`, `PRINT #1, "HI"`},
+		{`print#1, A`, `PRINT #1, A`},
+		{`INPUT#2, A$, B`, `INPUT #2, A$, B`},
+		{`LINE INPUT#2, A$`, `LINE INPUT #2, A$`},
+		{`CLOSE#1`, `CLOSE #1`},
+		{`GET#1, 2`, `GET #1, 2`},
+		{`PUT#1, 2`, `PUT #1, 2`},
+		{`FIELD#1, 4 AS R$`, `FIELD #1, 4 AS R$`},
+		{`A# = 1.5`, `A# = 1.5`},
+		{`PRINT A#, B%`, `PRINT A#, B%`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog := parseSource(t, "10 "+tt.src+"\n")
+			if got := prog.Stmts[10].String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
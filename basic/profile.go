@@ -0,0 +1,267 @@
+/**************************************************************/
+/*
+   profile.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Profile records how often each line runs and how long it takes. Set
+// Interpreter.Profile to a new Profile to record a run; several runs may
+// share one Profile.
+//
+// The time of a statement lasts until the next one starts. Self time is
+// the time of a line's own statement; total time adds the subroutines it
+// calls with GOSUB.
+type Profile struct {
+	Name string // program name used in pprof output
+
+	lines   map[int]*LineProfile
+	samples map[string]*profSample // keyed by call stack
+
+	last  time.Time
+	stack []int       // line numbers of the running statement and its GOSUB callers
+	cur   *profSample // sample of stack, nil when not running
+	key   []byte      // buffer for sample keys
+}
+
+// LineProfile is the profile of one line.
+type LineProfile struct {
+	Line   int
+	Source string
+	Count  int
+	Self   time.Duration
+	Total  time.Duration
+}
+
+type profSample struct {
+	stack []int // line numbers, running statement first
+	count int64
+	time  time.Duration
+}
+
+func NewProfile() *Profile {
+	return &Profile{lines: map[int]*LineProfile{}, samples: map[string]*profSample{}}
+}
+
+// enter is called when the statement at index pc starts.
+func (p *Profile) enter(it *Interpreter, lines []int, pc int) {
+	now := time.Now()
+	p.charge(now)
+
+	lineNo := lines[pc]
+	lp, ok := p.lines[lineNo]
+	if !ok {
+		lp = &LineProfile{Line: lineNo, Source: it.Prog.Source[lineNo]}
+		p.lines[lineNo] = lp
+	}
+	lp.Count++
+
+	p.stack = append(p.stack[:0], lineNo)
+	for i := len(it.stack) - 1; i >= 0; i-- {
+		p.stack = append(p.stack, lines[it.stack[i]-1])
+	}
+	p.cur = p.sample()
+	p.cur.count++
+	p.last = now
+}
+
+// stop ends the time of the last statement.
+func (p *Profile) stop() {
+	p.charge(time.Now())
+	p.cur = nil
+}
+
+func (p *Profile) charge(now time.Time) {
+	if p.cur == nil {
+		return
+	}
+	d := now.Sub(p.last)
+	p.cur.time += d
+	p.lines[p.stack[0]].Self += d
+	for i, ln := range p.stack {
+		if !containsInt(p.stack[:i], ln) {
+			p.lines[ln].Total += d
+		}
+	}
+}
+
+func (p *Profile) sample() *profSample {
+	p.key = p.key[:0]
+	for _, ln := range p.stack {
+		p.key = strconv.AppendInt(p.key, int64(ln), 10)
+		p.key = append(p.key, ',')
+	}
+	s, ok := p.samples[string(p.key)]
+	if !ok {
+		s = &profSample{stack: append([]int(nil), p.stack...)}
+		p.samples[string(p.key)] = s
+	}
+	return s
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Lines returns the profile of each line that ran, by decreasing self
+// time.
+func (p *Profile) Lines() []*LineProfile {
+	out := make([]*LineProfile, 0, len(p.lines))
+	for _, lp := range p.lines {
+		out = append(out, lp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Self != out[j].Self {
+			return out[i].Self > out[j].Self
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out
+}
+
+// Report writes Lines as a table.
+func (p *Profile) Report(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%6s %10s %12s %12s  %s\n", "LINE", "COUNT", "SELF", "TOTAL", "SOURCE"); err != nil {
+		return err
+	}
+	for _, lp := range p.Lines() {
+		_, err := fmt.Fprintf(w, "%6d %10d %12s %12s  %s\n", lp.Line, lp.Count,
+			lp.Self.Round(time.Microsecond), lp.Total.Round(time.Microsecond), lp.Source)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePprof writes the profile in the gzipped protocol buffer format
+// read by go tool pprof. Each line is a function named after its source,
+// so pprof's flat and cum columns are the self and total times.
+func (p *Profile) WritePprof(w io.Writer) error {
+	var pb protoBuf
+	strs := map[string]int64{}
+	str := func(s string) int64 {
+		if i, ok := strs[s]; ok {
+			return i
+		}
+		i := int64(len(strs))
+		strs[s] = i
+		return i
+	}
+	str("")
+
+	valueType := func(typ, unit string) []byte {
+		var vt protoBuf
+		vt.varintField(1, uint64(str(typ)))
+		vt.varintField(2, uint64(str(unit)))
+		return vt.b
+	}
+	pb.bytesField(1, valueType("samples", "count"))
+	pb.bytesField(1, valueType("time", "nanoseconds"))
+
+	keys := make([]string, 0, len(p.samples))
+	for k := range p.samples {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := p.samples[k]
+		var sb protoBuf
+		ids := make([]uint64, len(s.stack))
+		for i, ln := range s.stack {
+			ids[i] = uint64(ln)
+		}
+		sb.packedField(1, ids)
+		sb.packedField(2, []uint64{uint64(s.count), uint64(s.time.Nanoseconds())})
+		pb.bytesField(2, sb.b)
+	}
+
+	lineNos := make([]int, 0, len(p.lines))
+	for ln := range p.lines {
+		lineNos = append(lineNos, ln)
+	}
+	sort.Ints(lineNos)
+	for _, ln := range lineNos {
+		// location and function ids are the line number
+		var line, loc protoBuf
+		line.varintField(1, uint64(ln))
+		line.varintField(2, uint64(ln))
+		loc.varintField(1, uint64(ln))
+		loc.bytesField(4, line.b)
+		pb.bytesField(4, loc.b)
+	}
+	for _, ln := range lineNos {
+		var fn protoBuf
+		name := fmt.Sprintf("%d %s", ln, p.lines[ln].Source)
+		fn.varintField(1, uint64(ln))
+		fn.varintField(2, uint64(str(name)))
+		fn.varintField(3, uint64(str(name)))
+		fn.varintField(4, uint64(str(p.Name)))
+		fn.varintField(5, uint64(ln))
+		pb.bytesField(5, fn.b)
+	}
+
+	table := make([]string, len(strs))
+	for s, i := range strs {
+		table[i] = s
+	}
+	for _, s := range table {
+		pb.bytesField(6, []byte(s))
+	}
+	pb.bytesField(11, valueType("time", "nanoseconds"))
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(pb.b); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// protoBuf encodes protocol buffer fields.
+type protoBuf struct {
+	b []byte
+}
+
+func (pb *protoBuf) varint(v uint64) {
+	for v >= 0x80 {
+		pb.b = append(pb.b, byte(v)|0x80)
+		v >>= 7
+	}
+	pb.b = append(pb.b, byte(v))
+}
+
+func (pb *protoBuf) varintField(field int, v uint64) {
+	pb.varint(uint64(field) << 3)
+	pb.varint(v)
+}
+
+func (pb *protoBuf) bytesField(field int, b []byte) {
+	pb.varint(uint64(field)<<3 | 2)
+	pb.varint(uint64(len(b)))
+	pb.b = append(pb.b, b...)
+}
+
+func (pb *protoBuf) packedField(field int, vs []uint64) {
+	var inner protoBuf
+	for _, v := range vs {
+		inner.varint(v)
+	}
+	pb.bytesField(field, inner.b)
+}
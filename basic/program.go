@@ -0,0 +1,409 @@
+/**************************************************************/
+/*
+   parser.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Program struct {
+	Source map[int]string // for LIST
+	Stmts  map[int]Stmt   // for execution
+
+	lines    map[int]preparedLine // execution form of each line, built by SetLine
+	linked   *Linked              // nil until Link; kept up to date by SetLine/DeleteLine
+	syms     *Symbols             // variable slots used by the prepared statements
+	optimize bool                 // prepare optimized statements
+}
+
+// preparedLine is a statement made ready to run when its line is entered.
+type preparedLine struct {
+	stmt Stmt     // variables resolved and, if enabled, optimized
+	fn   stmtFunc // stmt compiled to closures
+}
+
+func NewProgram() *Program {
+	return &Program{
+		Source: map[int]string{},
+		Stmts:  map[int]Stmt{},
+		lines:  map[int]preparedLine{},
+		syms:   NewSymbols(),
+	}
+}
+
+func (p *Program) Clear() {
+	p.Source = map[int]string{}
+	p.Stmts = map[int]Stmt{}
+	p.lines = map[int]preparedLine{}
+	p.linked = nil
+	p.syms = NewSymbols()
+}
+
+func (p *Program) SetLine(lineNo int, src string, stmt Stmt) {
+	_, wasDef := p.Stmts[lineNo].(*DefStmt)
+	p.Source[lineNo] = src
+	p.Stmts[lineNo] = stmt
+	p.lines[lineNo] = p.prepare(stmt)
+	if p.linked != nil {
+		p.linked.set(lineNo, stmt, p.lines[lineNo])
+	}
+	if _, isDef := stmt.(*DefStmt); isDef || wasDef {
+		p.retype()
+	}
+}
+
+// prepare resolves the variables of stmt, optimizes it if enabled and
+// compiles it to closures.
+func (p *Program) prepare(stmt Stmt) preparedLine {
+	resolveVars(stmt, p.syms)
+	if p.optimize {
+		stmt = Optimize(stmt)
+	}
+	return preparedLine{stmt: stmt, fn: compileStmt(stmt)}
+}
+
+func (p *Program) DeleteLine(lineNo int) {
+	_, wasDef := p.Stmts[lineNo].(*DefStmt)
+	defer func() {
+		if wasDef {
+			p.retype()
+		}
+	}()
+	delete(p.Source, lineNo)
+	delete(p.Stmts, lineNo)
+	delete(p.lines, lineNo)
+	if p.linked != nil {
+		p.linked.remove(lineNo)
+	}
+}
+
+func (p *Program) OrderedLines() []int {
+	keys := make([]int, 0, len(p.Stmts))
+	for k := range p.Stmts {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// SetOptimize selects whether statements are run through Optimize.
+func (p *Program) SetOptimize(on bool) {
+	if p.optimize == on {
+		return
+	}
+	p.optimize = on
+	p.reprepare()
+}
+
+// retype applies the DEFtype statements to variables without suffix. When
+// their types change, every line is prepared again.
+func (p *Program) retype() {
+	var defs [26]varDef
+	for _, ln := range p.OrderedLines() {
+		s, ok := p.Stmts[ln].(*DefStmt)
+		if !ok {
+			continue
+		}
+		for _, r := range s.Ranges {
+			for c := r.From; c <= r.To; c++ {
+				defs[c-'A'] = varDef{isStr: s.Str, typ: s.Type}
+			}
+		}
+	}
+	if defs == p.syms.defs {
+		return
+	}
+	p.syms.setDefs(defs)
+	p.reprepare()
+}
+
+func (p *Program) reprepare() {
+	for ln, stmt := range p.Stmts {
+		p.lines[ln] = p.prepare(stmt)
+	}
+	p.linked = nil
+}
+
+func (p *Program) Optimized() bool { return p.optimize }
+
+// Link returns the program in executable form. It is built on first use
+// and then updated line by line, so repeated RUNs do not rebuild it. An
+// error is returned when a statement jumps to a line that does not exist.
+func (p *Program) Link() (*Linked, error) {
+	if p.linked == nil {
+		p.linked = newLinked(p)
+	}
+	return p.linked, p.linked.check()
+}
+
+// Linked holds the statements of a Program in line order with the jump
+// target of each statement resolved to a statement index.
+type Linked struct {
+	Lines  []int  // line number of each statement
+	Stmts  []Stmt // statements in line order, optimized if enabled
+	Funcs  []stmtFunc
+	Refs   []int // line each statement jumps to, 0 if none
+	Target []int // statement index of Refs, -1 if none or undefined
+
+	index      map[int]int // line number -> statement index
+	unresolved int         // statements whose Refs line does not exist
+	code       *Bytecode   // compiled form, nil until needed
+}
+
+func newLinked(p *Program) *Linked {
+	lp := &Linked{index: map[int]int{}}
+	for _, ln := range p.OrderedLines() {
+		lp.Lines = append(lp.Lines, ln)
+		lp.Stmts = append(lp.Stmts, p.lines[ln].stmt)
+		lp.Funcs = append(lp.Funcs, p.lines[ln].fn)
+		lp.Refs = append(lp.Refs, jumpRef(p.Stmts[ln]))
+		lp.Target = append(lp.Target, -1)
+	}
+	lp.reindex(0)
+	for i := range lp.Stmts {
+		lp.resolve(i)
+	}
+	return lp
+}
+
+// jumpRef returns the line a statement transfers control to, 0 if none.
+func jumpRef(stmt Stmt) int {
+	switch s := stmt.(type) {
+	case *GotoStmt:
+		return s.Line
+	case *GosubStmt:
+		return s.Line
+	case *IfStmt:
+		if s.HasLine {
+			return s.ThenLine
+		}
+		return jumpRef(s.ThenStmt)
+	case *OnErrorStmt:
+		return s.Line
+	case *ResumeStmt:
+		return s.Line
+	}
+	return 0
+}
+
+// resolveVars assigns a slot to every variable in stmt.
+func resolveVars(stmt Stmt, syms *Symbols) {
+	switch s := stmt.(type) {
+	case *LetStmt:
+		if s.Index == nil {
+			s.Slot = syms.Slot(s.Name)
+		}
+		for _, e := range s.Index {
+			resolveExprVars(e, syms)
+		}
+		resolveExprVars(s.Expr, syms)
+	case *DimStmt:
+		for _, bounds := range s.Bounds {
+			for _, e := range bounds {
+				resolveExprVars(e, syms)
+			}
+		}
+	case *SnapshotStmt:
+		resolveExprVars(s.Name, syms)
+	case *CSVStmt:
+		resolveExprVars(s.Name, syms)
+		if s.Count != "" {
+			s.Slot = syms.Slot(s.Count)
+		}
+	case *InputStmt:
+		s.Slot = syms.Slot(s.Name)
+	case *PrintStmt:
+		for _, e := range []Expr{s.File, s.Using} {
+			if e != nil {
+				resolveExprVars(e, syms)
+			}
+		}
+		for _, e := range s.Exprs {
+			resolveExprVars(e, syms)
+		}
+	case *IfStmt:
+		resolveExprVars(s.Cond, syms)
+		if !s.HasLine {
+			resolveVars(s.ThenStmt, syms)
+		}
+	case *ErrorStmt:
+		resolveExprVars(s.Code, syms)
+	case *RandomizeStmt:
+		resolveExprVars(s.Seed, syms)
+	case *ExtStmt:
+		for _, e := range s.Args {
+			resolveExprVars(e, syms)
+		}
+	case *OpenStmt:
+		resolveExprVars(s.Name, syms)
+		resolveExprVars(s.File, syms)
+		if s.Len != nil {
+			resolveExprVars(s.Len, syms)
+		}
+	case *FieldStmt:
+		resolveExprVars(s.File, syms)
+		s.Slots = s.Slots[:0]
+		for i, name := range s.Names {
+			resolveExprVars(s.Widths[i], syms)
+			s.Slots = append(s.Slots, syms.Slot(name))
+		}
+	case *RecordStmt:
+		resolveExprVars(s.File, syms)
+		if s.Record != nil {
+			resolveExprVars(s.Record, syms)
+		}
+	case *LsetStmt:
+		s.Slot = syms.Slot(s.Name)
+		resolveExprVars(s.Expr, syms)
+	case *CloseStmt:
+		for _, e := range s.Files {
+			resolveExprVars(e, syms)
+		}
+	case *FileInputStmt:
+		resolveExprVars(s.File, syms)
+		s.Slots = s.Slots[:0]
+		for _, name := range s.Names {
+			s.Slots = append(s.Slots, syms.Slot(name))
+		}
+	}
+}
+
+func resolveExprVars(e Expr, syms *Symbols) {
+	switch x := e.(type) {
+	case *VarRef:
+		x.Slot = syms.Slot(x.Name)
+		x.IsStr, x.Type = syms.isStr[x.Slot], syms.types[x.Slot]
+	case *FuncExpr:
+		for _, a := range x.Args {
+			resolveExprVars(a, syms)
+		}
+	case *UnaryExpr:
+		resolveExprVars(x.Rhs, syms)
+	case *BinaryExpr:
+		resolveExprVars(x.Lhs, syms)
+		resolveExprVars(x.Rhs, syms)
+	}
+}
+
+// Index returns the statement index of lineNo.
+func (lp *Linked) Index(lineNo int) (int, bool) {
+	idx, ok := lp.index[lineNo]
+	return idx, ok
+}
+
+func (lp *Linked) reindex(from int) {
+	for i := from; i < len(lp.Lines); i++ {
+		lp.index[lp.Lines[i]] = i
+	}
+}
+
+// resolve sets Target[i] from Refs[i].
+func (lp *Linked) resolve(i int) {
+	if lp.Refs[i] == 0 {
+		return
+	}
+	if idx, ok := lp.index[lp.Refs[i]]; ok {
+		lp.Target[i] = idx
+		return
+	}
+	lp.unresolved++
+}
+
+// unresolve drops statement i from the unresolved count.
+func (lp *Linked) unresolve(i int) {
+	if lp.Refs[i] != 0 && lp.Target[i] < 0 {
+		lp.unresolved--
+	}
+	lp.Target[i] = -1
+}
+
+// set adds or replaces a line. Jump targets are taken from the original
+// statement, so a line the optimizer dropped is checked like any other.
+func (lp *Linked) set(lineNo int, stmt Stmt, pl preparedLine) {
+	lp.code = nil
+	if i, ok := lp.index[lineNo]; ok {
+		lp.unresolve(i)
+		lp.Stmts[i] = pl.stmt
+		lp.Funcs[i] = pl.fn
+		lp.Refs[i] = jumpRef(stmt)
+		lp.resolve(i)
+		return
+	}
+
+	i := sort.SearchInts(lp.Lines, lineNo)
+	lp.Lines = insertAt(lp.Lines, i, lineNo)
+	lp.Stmts = insertAt(lp.Stmts, i, pl.stmt)
+	lp.Funcs = insertAt(lp.Funcs, i, pl.fn)
+	lp.Refs = insertAt(lp.Refs, i, jumpRef(stmt))
+	lp.Target = insertAt(lp.Target, i, -1)
+	lp.reindex(i)
+	for j := range lp.Target {
+		switch {
+		case j == i:
+			lp.resolve(j)
+		case lp.Target[j] >= i:
+			lp.Target[j]++
+		case lp.Refs[j] == lineNo:
+			lp.Target[j] = i
+			lp.unresolved--
+		}
+	}
+}
+
+func (lp *Linked) remove(lineNo int) {
+	i, ok := lp.index[lineNo]
+	if !ok {
+		return
+	}
+	lp.code = nil
+	lp.unresolve(i)
+	delete(lp.index, lineNo)
+	lp.Lines = append(lp.Lines[:i], lp.Lines[i+1:]...)
+	lp.Stmts = append(lp.Stmts[:i], lp.Stmts[i+1:]...)
+	lp.Funcs = append(lp.Funcs[:i], lp.Funcs[i+1:]...)
+	lp.Refs = append(lp.Refs[:i], lp.Refs[i+1:]...)
+	lp.Target = append(lp.Target[:i], lp.Target[i+1:]...)
+	lp.reindex(i)
+	for j, t := range lp.Target {
+		switch {
+		case t == i:
+			lp.Target[j] = -1
+			lp.unresolved++
+		case t > i:
+			lp.Target[j]--
+		}
+	}
+}
+
+// check reports the first statement that jumps to an undefined line.
+func (lp *Linked) check() error {
+	if lp.unresolved == 0 {
+		return nil
+	}
+	for i, ref := range lp.Refs {
+		if ref != 0 && lp.Target[i] < 0 {
+			return &BasicError{
+				Code: ErrUndefinedLine,
+				Line: lp.Lines[i],
+				Stmt: lp.Stmts[i].String(),
+				Msg:  fmt.Sprintf("undefined line %d", ref),
+			}
+		}
+	}
+	return nil
+}
+
+func insertAt[T any](s []T, i int, v T) []T {
+	var zero T
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
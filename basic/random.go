@@ -0,0 +1,55 @@
+/**************************************************************/
+/*
+   random.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// seed restarts RND from seed. The sequence depends only on the seed, so
+// output is the same across runs and machines.
+func (it *Interpreter) seed(seed float64) {
+	s := math.Float64bits(seed)
+	if it.pcg == nil {
+		it.pcg = rand.NewPCG(s, 0)
+		it.rng = rand.New(it.pcg)
+	} else {
+		it.pcg.Seed(s, 0)
+	}
+	it.rnd = 0
+}
+
+func (it *Interpreter) randomize(v Value) error {
+	if v.Kind != ValNumber {
+		return newError(ErrTypeMismatch, "RANDOMIZE requires number")
+	}
+	it.seed(v.Num)
+	return nil
+}
+
+func (it *Interpreter) nextRnd() float64 {
+	it.rnd = it.rng.Float64()
+	return it.rnd
+}
+
+// rndFunc implements RND(x): a negative x reseeds with x and returns the
+// first value, so the same x always gives the same value; 0 repeats the
+// last value; a positive x returns the next value.
+func (it *Interpreter) rndFunc(x float64) float64 {
+	switch {
+	case x < 0:
+		it.seed(x)
+		return it.nextRnd()
+	case x == 0:
+		return it.rnd
+	default:
+		return it.nextRnd()
+	}
+}
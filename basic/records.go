@@ -0,0 +1,280 @@
+/**************************************************************/
+/*
+   records.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// defaultRecordLen is the record length of OPEN ... FOR RANDOM without LEN.
+const defaultRecordLen = 128
+
+// field maps a string variable onto part of a record buffer.
+type field struct {
+	slot  int
+	off   int
+	width int
+}
+
+// fieldStmt runs FIELD #n, width AS var$, ...
+func (it *Interpreter) fieldStmt(s *FieldStmt) error {
+	f, err := it.file(s.File, modeRandom)
+	if err != nil {
+		return err
+	}
+	var fields []field
+	off := 0
+	for i, e := range s.Widths {
+		v, err := it.evalExpr(e)
+		if err != nil {
+			return err
+		}
+		if v.Kind != ValNumber {
+			return newError(ErrTypeMismatch, "FIELD width must be numeric")
+		}
+		width := int(v.Num)
+		if width < 0 || off+width > len(f.buf) {
+			return newError(ErrFieldOverflow, "FIELD is longer than the record")
+		}
+		slot := s.Slots[i]
+		if !it.Env.syms.isStr[slot] {
+			return newError(ErrTypeMismatch, "FIELD requires string variable %s", s.Names[i])
+		}
+		fields = append(fields, field{slot: slot, off: off, width: width})
+		off += width
+	}
+	for _, other := range it.files {
+		other.fields = slices.DeleteFunc(other.fields, func(x field) bool {
+			return slices.ContainsFunc(fields, func(y field) bool { return x.slot == y.slot })
+		})
+	}
+	f.fields = append(f.fields, fields...)
+	return it.loadFields(f, fields)
+}
+
+// loadFields sets the variables of fields to their part of the buffer.
+func (it *Interpreter) loadFields(f *openFile, fields []field) error {
+	for _, fd := range fields {
+		if err := it.setSlot(fd.slot, StringValue(string(f.buf[fd.off:fd.off+fd.width]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record runs GET and PUT.
+func (it *Interpreter) record(s *RecordStmt) error {
+	f, err := it.file(s.File, modeRandom)
+	if err != nil {
+		return err
+	}
+	rec := f.rec + 1
+	if s.Record != nil {
+		v, err := it.evalExpr(s.Record)
+		if err != nil {
+			return err
+		}
+		if v.Kind != ValNumber {
+			return newError(ErrTypeMismatch, "record number must be numeric")
+		}
+		if v.Num < 1 || v.Num > math.MaxInt32 {
+			return newError(ErrBadRecordNumber, "bad record number %s", v)
+		}
+		rec = int64(v.Num)
+	}
+	if _, err := f.rw.Seek((rec-1)*int64(len(f.buf)), io.SeekStart); err != nil {
+		return err
+	}
+	f.rec = rec
+	if s.Put {
+		_, err := f.rw.Write(f.buf)
+		return err
+	}
+	n, err := io.ReadFull(f.rw, f.buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	clear(f.buf[n:])
+	f.eof = n < len(f.buf)
+	return it.loadFields(f, f.fields)
+}
+
+// lset runs LSET and RSET. A field variable is justified in its part of
+// the buffer, any other string variable within its current length.
+func (it *Interpreter) lset(s *LsetStmt) error {
+	v, err := it.evalExpr(s.Expr)
+	if err != nil {
+		return err
+	}
+	if v.Kind != ValString || !it.Env.syms.isStr[s.Slot] {
+		return newError(ErrTypeMismatch, "%s requires strings", s.keyword())
+	}
+	buf := []byte(it.Env.load(s.Slot).Str)
+	for _, f := range it.files {
+		if i := slices.IndexFunc(f.fields, func(x field) bool { return x.slot == s.Slot }); i >= 0 {
+			fd := f.fields[i]
+			buf = f.buf[fd.off : fd.off+fd.width]
+		}
+	}
+
+	str := v.Str
+	if len(str) > len(buf) {
+		str = str[:len(buf)]
+	}
+	pad := strings.Repeat(" ", len(buf)-len(str))
+	if s.Right {
+		copy(buf, pad+str)
+	} else {
+		copy(buf, str+pad)
+	}
+	return it.setSlot(s.Slot, StringValue(string(buf)))
+}
+
+// MKI$, MKS$ and MKD$ and their inverses CVI, CVS and CVD use the byte
+// layouts of Microsoft BASIC: a little-endian int16, and singles and
+// doubles in the Microsoft Binary Format of GW-BASIC.
+
+func mki(it *Interpreter, args []Value) (Value, error) {
+	v, err := convArg("MKI$", args)
+	if err != nil {
+		return Value{}, err
+	}
+	if v, err = toType(v, Integer); err != nil {
+		return Value{}, err
+	}
+	b := binary.LittleEndian.AppendUint16(nil, uint16(int16(v.Num)))
+	return StringValue(string(b)), nil
+}
+
+func mks(it *Interpreter, args []Value) (Value, error) {
+	v, err := convArg("MKS$", args)
+	if err != nil {
+		return Value{}, err
+	}
+	b, err := mbfSingle(float32(v.Num))
+	return StringValue(string(b)), err
+}
+
+func mkd(it *Interpreter, args []Value) (Value, error) {
+	v, err := convArg("MKD$", args)
+	if err != nil {
+		return Value{}, err
+	}
+	b, err := mbfDouble(v.Num)
+	return StringValue(string(b)), err
+}
+
+func cvi(it *Interpreter, args []Value) (Value, error) {
+	b, err := convBytes("CVI", args, 2)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Kind: ValNumber, Num: float64(int16(binary.LittleEndian.Uint16(b))), Type: Integer}, nil
+}
+
+func cvs(it *Interpreter, args []Value) (Value, error) {
+	b, err := convBytes("CVS", args, 4)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Kind: ValNumber, Num: float64(fromMBFSingle(b)), Type: Single}, nil
+}
+
+func cvd(it *Interpreter, args []Value) (Value, error) {
+	b, err := convBytes("CVD", args, 8)
+	if err != nil {
+		return Value{}, err
+	}
+	return NumberValue(fromMBFDouble(b)), nil
+}
+
+func convArg(name string, args []Value) (Value, error) {
+	if len(args) != 1 {
+		return Value{}, newError(ErrIllegalFunction, "%s takes 1 argument", name)
+	}
+	if args[0].Kind != ValNumber {
+		return Value{}, newError(ErrTypeMismatch, "%s requires number", name)
+	}
+	return args[0], nil
+}
+
+func convBytes(name string, args []Value, n int) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, newError(ErrIllegalFunction, "%s takes 1 argument", name)
+	}
+	if args[0].Kind != ValString {
+		return nil, newError(ErrTypeMismatch, "%s requires string", name)
+	}
+	if len(args[0].Str) < n {
+		return nil, newError(ErrIllegalFunction, "%s requires %d bytes", name, n)
+	}
+	return []byte(args[0].Str[:n]), nil
+}
+
+// mbfSingle encodes f as an MBF single: 23 mantissa bits, the sign in bit
+// 7 of byte 2 and the exponent, biased by 129, in byte 3. 0 is all zero.
+func mbfSingle(f float32) ([]byte, error) {
+	bits := math.Float32bits(f)
+	exp := bits >> 23 & 0xff
+	if exp == 0 { // zero or too small
+		return make([]byte, 4), nil
+	}
+	if exp == 0xff || exp+2 > 0xff {
+		return nil, newError(ErrOverflow, "overflow")
+	}
+	b := binary.LittleEndian.AppendUint32(nil, bits&0x7fffff)
+	b[2] |= byte(bits>>31) << 7
+	b[3] = byte(exp + 2)
+	return b, nil
+}
+
+func fromMBFSingle(b []byte) float32 {
+	if b[3] <= 2 {
+		return 0
+	}
+	sign := uint32(b[2]>>7) << 31
+	mant := binary.LittleEndian.Uint32([]byte{b[0], b[1], b[2] & 0x7f, 0})
+	return math.Float32frombits(sign | uint32(b[3]-2)<<23 | mant)
+}
+
+// mbfDouble encodes n as an MBF double: 55 mantissa bits, the sign in
+// bit 7 of byte 6 and the exponent, biased by 129, in byte 7. Its range
+// is that of a single.
+func mbfDouble(n float64) ([]byte, error) {
+	bits := math.Float64bits(n)
+	exp := int(bits >> 52 & 0x7ff)
+	e := exp - 1023 + 129
+	if exp == 0 || e <= 0 {
+		return make([]byte, 8), nil
+	}
+	if exp == 0x7ff || e > 0xff {
+		return nil, newError(ErrOverflow, "overflow")
+	}
+	b := binary.LittleEndian.AppendUint64(nil, (bits&(1<<52-1))<<3)
+	b[6] |= byte(bits>>63) << 7
+	b[7] = byte(e)
+	return b, nil
+}
+
+func fromMBFDouble(b []byte) float64 {
+	if b[7] == 0 {
+		return 0
+	}
+	m := make([]byte, 8)
+	copy(m, b[:7])
+	m[6] &= 0x7f
+	sign := uint64(b[6]>>7) << 63
+	mant := binary.LittleEndian.Uint64(m) >> 3
+	return math.Float64frombits(sign | uint64(int(b[7])-129+1023)<<52 | mant)
+}
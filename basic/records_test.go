@@ -0,0 +1,124 @@
+/**************************************************************/
+/*
+   records_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+)
+
+// TestMBF checks the byte layouts of Microsoft BASIC.
+func TestMBF(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(*Interpreter, []Value) (Value, error)
+		n    float64
+		want []byte
+	}{
+		{"MKI$", mki, 1, []byte{0x01, 0x00}},
+		{"MKI$", mki, -2, []byte{0xFE, 0xFF}},
+		{"MKI$", mki, 256, []byte{0x00, 0x01}},
+		{"MKI$", mki, 32767, []byte{0xFF, 0x7F}},
+		{"MKI$", mki, -32768, []byte{0x00, 0x80}},
+		{"MKS$", mks, 0, []byte{0x00, 0x00, 0x00, 0x00}},
+		{"MKS$", mks, 1, []byte{0x00, 0x00, 0x00, 0x81}},
+		{"MKS$", mks, 1.5, []byte{0x00, 0x00, 0x40, 0x81}},
+		{"MKS$", mks, -1.5, []byte{0x00, 0x00, 0xC0, 0x81}},
+		{"MKS$", mks, 0.5, []byte{0x00, 0x00, 0x00, 0x80}},
+		{"MKS$", mks, 100, []byte{0x00, 0x00, 0x48, 0x87}},
+		{"MKD$", mkd, 0, []byte{0, 0, 0, 0, 0, 0, 0x00, 0x00}},
+		{"MKD$", mkd, 1, []byte{0, 0, 0, 0, 0, 0, 0x00, 0x81}},
+		{"MKD$", mkd, 1.5, []byte{0, 0, 0, 0, 0, 0, 0x40, 0x81}},
+		{"MKD$", mkd, -100, []byte{0, 0, 0, 0, 0, 0, 0xC8, 0x87}},
+	}
+	it := NewInterpreter()
+	for _, tt := range tests {
+		v, err := tt.fn(it, []Value{NumberValue(tt.n)})
+		if err != nil {
+			t.Errorf("%s(%g): %v", tt.name, tt.n, err)
+			continue
+		}
+		if got := []byte(v.Str); !bytes.Equal(got, tt.want) {
+			t.Errorf("%s(%g) = % X, want % X", tt.name, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMBFRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		mk, cv func(*Interpreter, []Value) (Value, error)
+		n      float64
+	}{
+		{"MKI$", mki, cvi, -12345},
+		{"MKS$", mks, cvs, 3.25},
+		{"MKS$", mks, cvs, -0.001953125},
+		{"MKD$", mkd, cvd, 123456.789},
+		{"MKD$", mkd, cvd, -1e-10},
+	}
+	it := NewInterpreter()
+	for _, tt := range tests {
+		s, err := tt.mk(it, []Value{NumberValue(tt.n)})
+		if err != nil {
+			t.Errorf("%s(%g): %v", tt.name, tt.n, err)
+			continue
+		}
+		v, err := tt.cv(it, []Value{s})
+		if err != nil {
+			t.Errorf("converting %s(%g) back: %v", tt.name, tt.n, err)
+			continue
+		}
+		if v.Num != tt.n {
+			t.Errorf("converting %s(%g) back = %g", tt.name, tt.n, v.Num)
+		}
+	}
+}
+
+func TestMBFErrors(t *testing.T) {
+	it := NewInterpreter()
+	if _, err := mki(it, []Value{NumberValue(40000)}); err == nil {
+		t.Error("MKI$(40000) succeeded, want an overflow")
+	}
+	if _, err := cvi(it, []Value{StringValue("A")}); err == nil {
+		t.Error(`CVI("A") succeeded, want an error`)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	src := `10 OPEN "R.DAT" FOR RANDOM AS #1 LEN = 8
+20 FIELD #1, 3 AS N$, 2 AS I$, 3 AS T$
+30 LSET N$ = "AB"
+40 LSET I$ = MKI$(-2)
+50 RSET T$ = "Z"
+60 PUT #1, 2
+70 LSET N$ = "LONGER"
+80 PUT #1, 1
+90 GET #1, 2
+100 PRINT N$, CVI(I$), T$
+110 GET #1
+120 PRINT EOF(1)
+`
+	wantOut := "AB  -2   Z\n 1\n"
+	want := []byte("LON\xFE\xFF  Z" + "AB \xFE\xFF  Z")
+	for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+		fsys := NewMemFS()
+		got := runWith(parseSource(t, src), WithFS(fsys), WithEngine(engine))
+		if got != wantOut {
+			t.Errorf("engine %d: got\n%s\nwant\n%s", engine, got, wantOut)
+		}
+		data, err := fs.ReadFile(fsys, "R.DAT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("engine %d: R.DAT is %q, want %q", engine, data, want)
+		}
+	}
+}
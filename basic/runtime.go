@@ -0,0 +1,778 @@
+/**************************************************************/
+/*
+   parser.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ValueKind int
+
+const (
+	ValNumber ValueKind = iota
+	ValString
+)
+
+type Value struct {
+	Kind ValueKind
+	Num  float64
+	Str  string
+	Type NumType  // precision of a number
+	Dec  *big.Rat // exact value of a number in decimal mode, nil otherwise
+
+	lit bool // untyped numeric literal, see arithType
+}
+
+func NumberValue(n float64) Value { return Value{Kind: ValNumber, Num: n} }
+func StringValue(s string) Value  { return Value{Kind: ValString, Str: s} }
+
+func (v Value) String() string {
+	switch v.Kind {
+	case ValNumber:
+		if v.Dec != nil {
+			return formatDecimal(v.Dec)
+		}
+		switch v.Type {
+		case Integer:
+			return strconv.Itoa(int(v.Num))
+		case Single:
+			return strconv.FormatFloat(v.Num, 'g', 7, 32)
+		}
+		return strconv.FormatFloat(v.Num, 'g', -1, 64)
+	case ValString:
+		return v.Str
+	default:
+		return "<invalid>"
+	}
+}
+
+type Engine int
+
+const (
+	EngineTree    Engine = iota // walk the AST
+	EngineVM                    // compile to bytecode and run it on the VM
+	EngineClosure               // call the closures built when lines are entered
+)
+
+type Interpreter struct {
+	Prog     *Program
+	Env      *Env
+	In       *bufio.Reader
+	Out      io.Writer
+	MaxOps   int // infinit loop limitation (0: unlimited)
+	Limits   Limits
+	Engine   Engine
+	Profile  *Profile        // records the run when not nil
+	Coverage *Coverage       // records the run when not nil
+	Seed     float64         // RND seed at the start of each run, as set by RANDOMIZE
+	Decimal  *DecimalOptions // decimal mode from the start of each run when not nil
+
+	NumberFormat NumberFormat // how PRINT and STR$ format numbers
+	FS           WriteFS      // file system of OPEN
+
+	out   io.Writer // Out wrapped with the output limit during Run
+	lines []int     // line number of each statement
+	start time.Time
+	ops   int
+	pc    int   // index of the running statement
+	stack []int // GOSUB return addresses
+
+	errCode int // ERR: code of the last error
+	errLine int // ERL: line of the last error
+
+	onError   int         // index of the ON ERROR GOTO line, -1: trapping off
+	inHandler bool        // an error handler is running
+	errPC     int         // index of the statement that raised the error
+	pending   *BasicError // error being handled
+
+	funcs map[string]*hostFunc // registered by RegisterFunc
+	files map[int]*openFile    // by file number, closed when a run ends
+
+	dec *DecimalOptions // decimal mode, turned on by Decimal or OPTION DECIMAL
+
+	pcg *rand.PCG // source of RND, separate from the global one
+	rng *rand.Rand
+	rnd float64 // last RND value, returned by RND(0)
+
+	done <-chan struct{} // Done channel of the context passed to Run
+	ctx  context.Context
+}
+
+// Option configures an Interpreter created by NewInterpreter.
+type Option func(*Interpreter)
+
+// WithProgram sets the program to run.
+func WithProgram(prog *Program) Option {
+	return func(it *Interpreter) { it.Prog = prog }
+}
+
+// WithStdin sets the reader used by INPUT.
+func WithStdin(r io.Reader) Option {
+	return func(it *Interpreter) {
+		if br, ok := r.(*bufio.Reader); ok {
+			it.In = br
+		} else {
+			it.In = bufio.NewReader(r)
+		}
+	}
+}
+
+// WithStdout sets the writer used by PRINT.
+func WithStdout(w io.Writer) Option {
+	return func(it *Interpreter) { it.Out = w }
+}
+
+// WithEngine selects the execution engine.
+func WithEngine(e Engine) Option {
+	return func(it *Interpreter) { it.Engine = e }
+}
+
+// WithLimits sets the resource limits.
+func WithLimits(l Limits) Option {
+	return func(it *Interpreter) { it.Limits = l }
+}
+
+// WithProfile records runs in p.
+func WithProfile(p *Profile) Option {
+	return func(it *Interpreter) { it.Profile = p }
+}
+
+// WithCoverage records runs in c.
+func WithCoverage(c *Coverage) Option {
+	return func(it *Interpreter) { it.Coverage = c }
+}
+
+// WithSeed sets the RND seed, as RANDOMIZE seed does.
+func WithSeed(seed float64) Option {
+	return func(it *Interpreter) { it.Seed = seed }
+}
+
+// WithDecimal turns decimal mode on.
+func WithDecimal(opts DecimalOptions) Option {
+	return func(it *Interpreter) { it.Decimal = &opts }
+}
+
+// WithNumberFormat sets how PRINT and STR$ format numbers.
+func WithNumberFormat(f NumberFormat) Option {
+	return func(it *Interpreter) { it.NumberFormat = f }
+}
+
+// WithFS sets the file system used by OPEN.
+func WithFS(fsys WriteFS) Option {
+	return func(it *Interpreter) { it.FS = fsys }
+}
+
+// WithMaxOps sets the statement limit (0: unlimited).
+func WithMaxOps(n int) Option {
+	return func(it *Interpreter) { it.MaxOps = n }
+}
+
+// NewInterpreter returns an interpreter reading from os.Stdin, writing
+// to os.Stdout and opening files in the current directory unless
+// configured otherwise by opts.
+func NewInterpreter(opts ...Option) *Interpreter {
+	it := &Interpreter{
+		Prog:   NewProgram(),
+		Env:    NewEnv(),
+		In:     bufio.NewReader(os.Stdin),
+		Out:    os.Stdout,
+		FS:     DirFS("."),
+		MaxOps: 1_000_000,
+	}
+	for _, opt := range opts {
+		opt(it)
+	}
+	return it
+}
+
+func (it *Interpreter) ResetEnv() {
+	it.Env = NewEnv()
+}
+
+// Run runs the program from its first line. It stops with ctx.Err() when
+// ctx is cancelled.
+func (it *Interpreter) Run(ctx context.Context) error {
+	lp, err := it.Prog.Link()
+	if err != nil {
+		return err
+	}
+	if len(lp.Stmts) == 0 {
+		return nil
+	}
+	it.Env.bind(it.Prog.syms)
+	it.reset()
+	return it.run(ctx, lp, 0)
+}
+
+// run runs lp from the statement at index pc, switching to the snapshot
+// of a RESUME SNAPSHOT.
+func (it *Interpreter) run(ctx context.Context, lp *Linked, pc int) error {
+	it.ctx, it.done = ctx, ctx.Done()
+	defer it.closeFiles()
+	if it.Profile != nil {
+		defer it.Profile.stop()
+	}
+	for {
+		err := it.exec(lp, pc)
+		var rs *resumeSnapshot
+		if !errors.As(err, &rs) {
+			return err
+		}
+		if lp, err = it.restore(rs.snap); err != nil {
+			return err
+		}
+		pc = rs.snap.PC
+	}
+}
+
+// exec runs lp from the statement at index pc on the selected engine.
+func (it *Interpreter) exec(lp *Linked, pc int) error {
+	it.lines = lp.Lines
+	if it.Engine == EngineVM {
+		bc, err := lp.Compile()
+		if err != nil {
+			return err
+		}
+		return it.runVM(bc, pc)
+	}
+
+	for pc >= 0 && pc < len(lp.Stmts) {
+		if err := it.tick(pc); err != nil {
+			return err
+		}
+		lineNo := lp.Lines[pc]
+		stmt := lp.Stmts[pc]
+
+		var nextPC int
+		var end bool
+		var err error
+		if it.Engine == EngineClosure {
+			nextPC, end, err = lp.Funcs[pc](it, lp, pc)
+		} else {
+			nextPC, end, err = it.execStmt(stmt, lp, pc)
+		}
+		if err != nil {
+			if pc, err = it.trap(err, pc, lineNo, stmt); err != nil {
+				return err
+			}
+			continue
+		}
+		if end {
+			return nil
+		}
+		pc = nextPC
+	}
+	return it.finish()
+}
+
+// reset prepares the per-run state shared by all engines.
+func (it *Interpreter) reset() {
+	it.out = it.Out
+	if it.Limits.MaxOutputBytes > 0 {
+		it.out = &limitWriter{w: it.Out, max: it.Limits.MaxOutputBytes}
+	}
+	it.start = time.Now()
+	it.ops = 0
+	it.stack = it.stack[:0]
+	it.errCode, it.errLine = 0, 0
+	it.onError, it.inHandler, it.pending = -1, false, nil
+	it.seed(it.Seed)
+	it.dec = it.Decimal
+	it.Env.arrays = nil // erased by RUN, so DIM can run again
+}
+
+// tick is called before the statement at index pc. It enforces
+// cancellation, MaxOps and the time limit, and feeds the profiler and
+// coverage.
+func (it *Interpreter) tick(pc int) error {
+	it.pc = pc
+	if it.Profile != nil {
+		it.Profile.enter(it, it.lines, pc)
+	}
+	if it.Coverage != nil {
+		it.Coverage.hit(it.lines[pc])
+	}
+	if it.done != nil {
+		select {
+		case <-it.done:
+			return it.ctx.Err()
+		default:
+		}
+	}
+	if it.Limits.MaxDuration > 0 && time.Since(it.start) > it.Limits.MaxDuration {
+		return &TimeLimitError{Limit: it.Limits.MaxDuration}
+	}
+	if it.MaxOps > 0 {
+		it.ops++
+		if it.ops > it.MaxOps {
+			return fmt.Errorf("runtime error: operation limit exceeded (possible infinite loop)")
+		}
+	}
+	return nil
+}
+
+// trap records err raised by the statement at pc and returns the index of
+// the ON ERROR handler, or the error that ends the run.
+func (it *Interpreter) trap(err error, pc int, lineNo int, stmt Stmt) (int, error) {
+	var rs *resumeSnapshot
+	if errors.As(err, &rs) {
+		return 0, err
+	}
+	be := asBasicError(err, lineNo, stmt)
+	it.errCode, it.errLine = be.Code, be.Line
+	if it.onError < 0 || it.inHandler || !be.trappable() {
+		return 0, be
+	}
+	it.inHandler = true
+	it.errPC = pc
+	it.pending = be
+	return it.onError, nil
+}
+
+// finish is called when execution runs past the last line.
+func (it *Interpreter) finish() error {
+	if it.inHandler {
+		return &BasicError{Code: ErrNoResume, Msg: errorMessage(ErrNoResume)}
+	}
+	return nil
+}
+
+// execStmt runs stmt, the statement at index pc of lp, and returns the
+// index of the next statement.
+func (it *Interpreter) execStmt(stmt Stmt, lp *Linked, pc int) (int, bool, error) {
+	nextPC := pc + 1
+
+	switch s := stmt.(type) {
+	case *RemStmt:
+		return nextPC, false, nil
+
+	case *LetStmt:
+		if s.Index != nil {
+			return nextPC, false, it.letElement(s)
+		}
+		v, err := it.evalExpr(s.Expr)
+		if err != nil {
+			return 0, false, err
+		}
+		if err := it.setSlot(s.Slot, v); err != nil {
+			return 0, false, err
+		}
+		return nextPC, false, nil
+
+	case *PrintStmt:
+		w := it.out
+		if s.File != nil {
+			f, err := it.file(s.File, modeOutput, modeAppend)
+			if err != nil {
+				return 0, false, err
+			}
+			w = f.w
+		}
+		vals := make([]Value, 0, len(s.Exprs))
+		for _, e := range s.Exprs {
+			v, err := it.evalExpr(e)
+			if err != nil {
+				return 0, false, err
+			}
+			vals = append(vals, v)
+		}
+		if s.Using != nil {
+			format, err := it.evalExpr(s.Using)
+			if err != nil {
+				return 0, false, err
+			}
+			return nextPC, false, it.printUsing(w, format, vals)
+		}
+		if err := it.print(w, vals); err != nil {
+			return 0, false, err
+		}
+		return nextPC, false, nil
+
+	case *InputStmt:
+		v, err := it.input(s.Slot)
+		if err != nil {
+			return 0, false, err
+		}
+		if err := it.setSlot(s.Slot, v); err != nil {
+			return 0, false, err
+		}
+		return nextPC, false, nil
+
+	case *IfStmt:
+		cond, err := it.evalExpr(s.Cond)
+		if err != nil {
+			return 0, false, err
+		}
+		if cond.Kind != ValNumber {
+			return 0, false, newError(ErrTypeMismatch, "IF condition must be numeric")
+		}
+		if it.Coverage != nil {
+			it.Coverage.branch(lp.Lines[pc], ifDepth(lp.Stmts[pc], s), cond.Num != 0)
+		}
+		if cond.Num == 0 {
+			return nextPC, false, nil
+		}
+
+		if s.HasLine {
+			return lp.Target[pc], false, nil
+		}
+
+		return it.execStmt(s.ThenStmt, lp, pc)
+
+	case *GotoStmt:
+		return lp.Target[pc], false, nil
+
+	case *GosubStmt:
+		if err := it.gosub(nextPC); err != nil {
+			return 0, false, err
+		}
+		return lp.Target[pc], false, nil
+
+	case *ReturnStmt:
+		ret, err := it.ret()
+		if err != nil {
+			return 0, false, err
+		}
+		return ret, false, nil
+
+	case *OnErrorStmt:
+		if err := it.setOnError(lp.Target[pc]); err != nil {
+			return 0, false, err
+		}
+		return nextPC, false, nil
+
+	case *ResumeStmt:
+		target, err := it.resume(s, lp.Target[pc])
+		if err != nil {
+			return 0, false, err
+		}
+		return target, false, nil
+
+	case *ErrorStmt:
+		v, err := it.evalExpr(s.Code)
+		if err != nil {
+			return 0, false, err
+		}
+		return 0, false, raiseError(v)
+
+	case *DefStmt:
+		return nextPC, false, nil // applied when the program is linked
+
+	case *OptionStmt:
+		it.option(s)
+		return nextPC, false, nil
+
+	case *RandomizeStmt:
+		v, err := it.evalExpr(s.Seed)
+		if err != nil {
+			return 0, false, err
+		}
+		return nextPC, false, it.randomize(v)
+
+	case *OpenStmt:
+		return nextPC, false, it.open(s)
+
+	case *CloseStmt:
+		return nextPC, false, it.close(s)
+
+	case *FileInputStmt:
+		return nextPC, false, it.fileInput(s)
+
+	case *FieldStmt:
+		return nextPC, false, it.fieldStmt(s)
+
+	case *RecordStmt:
+		return nextPC, false, it.record(s)
+
+	case *LsetStmt:
+		return nextPC, false, it.lset(s)
+
+	case *SnapshotStmt:
+		return nextPC, false, it.snapshotStmt(s, pc)
+
+	case *DimStmt:
+		return nextPC, false, it.dimStmt(s)
+
+	case *CSVStmt:
+		if s.Write {
+			return nextPC, false, it.csvWrite(s)
+		}
+		return nextPC, false, it.csvRead(s)
+
+	case *ExtStmt:
+		return nextPC, false, it.execExt(s)
+
+	case *EndStmt:
+		return 0, true, nil
+
+	default:
+		return 0, false, newError(ErrSyntax, "unknown statement type %T", stmt)
+	}
+}
+
+// print writes vals to w separated by spaces and ends the line.
+func (it *Interpreter) print(w io.Writer, vals []Value) error {
+	parts := make([]string, 0, len(vals))
+	for _, v := range vals {
+		if v.Kind == ValNumber {
+			parts = append(parts, it.formatNumber(v))
+		} else {
+			parts = append(parts, v.String())
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.Join(parts, " "))
+	return err
+}
+
+// input prompts for and reads one value for the variable at slot.
+func (it *Interpreter) input(slot int) (Value, error) {
+	if _, err := fmt.Fprint(it.out, "? "); err != nil {
+		return Value{}, err
+	}
+	line, err := it.In.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Value{}, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if it.Env.syms.isStr[slot] {
+		return StringValue(line), nil
+	}
+	if it.dec != nil {
+		r, ok := parseDecimal(strings.TrimSpace(line))
+		if !ok {
+			return Value{}, newError(ErrTypeMismatch, "INPUT expects number")
+		}
+		return decimalValue(r), nil
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+	if err != nil {
+		return Value{}, newError(ErrTypeMismatch, "INPUT expects number")
+	}
+	return NumberValue(n), nil
+}
+
+// gosub pushes the return index ret.
+func (it *Interpreter) gosub(ret int) error {
+	if it.Limits.MaxGosubDepth > 0 && len(it.stack) >= it.Limits.MaxGosubDepth {
+		return &GosubDepthError{Limit: it.Limits.MaxGosubDepth}
+	}
+	it.stack = append(it.stack, ret)
+	return nil
+}
+
+// ret pops the index pushed by the matching GOSUB.
+func (it *Interpreter) ret() (int, error) {
+	if len(it.stack) == 0 {
+		return 0, newError(ErrReturnWithoutGosub, "RETURN without GOSUB")
+	}
+	ret := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	return ret, nil
+}
+
+// setOnError installs the handler at index idx, or turns trapping off
+// when idx is -1.
+func (it *Interpreter) setOnError(idx int) error {
+	if idx < 0 && it.inHandler {
+		// ON ERROR GOTO 0 inside a handler reports the pending error
+		return it.pending
+	}
+	it.onError = idx
+	return nil
+}
+
+// resume leaves the error handler and returns the index to continue at;
+// target is the index of the RESUME line, if any.
+func (it *Interpreter) resume(s *ResumeStmt, target int) (int, error) {
+	if !it.inHandler {
+		return 0, newError(ErrResumeWithoutError, "RESUME without error")
+	}
+	switch {
+	case s.Next:
+		target = it.errPC + 1
+	case s.Line == 0:
+		target = it.errPC
+	}
+	it.inHandler = false
+	it.pending = nil
+	return target, nil
+}
+
+// raiseError returns the error selected by ERROR n.
+func raiseError(v Value) error {
+	if v.Kind != ValNumber {
+		return newError(ErrTypeMismatch, "ERROR requires number")
+	}
+	code := int(v.Num)
+	if code < 1 || code > 255 {
+		return newError(ErrIllegalFunction, "illegal function call")
+	}
+	return newError(code, "%s", errorMessage(code))
+}
+
+// setSlot stores v in the variable at slot after checking its kind and
+// the string and variable limits.
+func (it *Interpreter) setSlot(slot int, v Value) error {
+	e := it.Env
+	if err := it.checkStore(v, !e.isSet[slot], e.count); err != nil {
+		return err
+	}
+	if err := checkKind(e.syms.names[slot], e.syms.isStr[slot], v); err != nil {
+		return err
+	}
+	if v.Kind == ValNumber && v.Type != e.syms.types[slot] {
+		var err error
+		if v, err = toType(v, e.syms.types[slot]); err != nil {
+			return err
+		}
+	}
+	e.store(slot, v)
+	return nil
+}
+
+// checkStore applies the string and variable limits to storing v; isNew
+// reports whether the variable is created, count how many exist.
+func (it *Interpreter) checkStore(v Value, isNew bool, count int) error {
+	if v.Kind == ValString && it.Limits.MaxStringLen > 0 && len(v.Str) > it.Limits.MaxStringLen {
+		return &StringLimitError{Limit: it.Limits.MaxStringLen, Len: len(v.Str)}
+	}
+	if isNew && it.Limits.MaxVariables > 0 && count >= it.Limits.MaxVariables {
+		return &VariableLimitError{Limit: it.Limits.MaxVariables}
+	}
+	return nil
+}
+
+func (it *Interpreter) evalExpr(e Expr) (Value, error) {
+	switch x := e.(type) {
+	case *NumberLit:
+		return it.number(x.Value), nil
+	case *StringLit:
+		return StringValue(x.Value), nil
+	case *VarRef:
+		return it.Env.load(x.Slot), nil
+	case *FuncExpr:
+		return it.evalFunc(x)
+
+	case *UnaryExpr:
+		v, err := it.evalExpr(x.Rhs)
+		if err != nil {
+			return Value{}, err
+		}
+		if v.Kind != ValNumber {
+			return Value{}, newError(ErrTypeMismatch, "unary %s requires number", x.Op)
+		}
+		switch x.Op {
+		case "+":
+			return v, nil
+		case "-":
+			return negate(v)
+		default:
+			return Value{}, newError(ErrSyntax, "unsupported unary op %s", x.Op)
+		}
+
+	case *BinaryExpr:
+		lv, err := it.evalExpr(x.Lhs)
+		if err != nil {
+			return Value{}, err
+		}
+		rv, err := it.evalExpr(x.Rhs)
+		if err != nil {
+			return Value{}, err
+		}
+		return it.binary(x.Op, lv, rv)
+
+	default:
+		return Value{}, newError(ErrSyntax, "unknown expression type %T", e)
+	}
+}
+
+func evalBinary(op string, l, r Value) (Value, error) {
+	v, err := evalBinaryOp(op, l, r)
+	if l.lit && r.lit && v.Kind == ValNumber {
+		v.lit = true
+	}
+	return v, err
+}
+
+func evalBinaryOp(op string, l, r Value) (Value, error) {
+	switch op {
+	case "+", "-", "*", "/", "\\":
+		if l.Kind != ValNumber || r.Kind != ValNumber {
+			return Value{}, newError(ErrTypeMismatch, "arithmetic requires numbers")
+		}
+		t := arithType(l, r)
+		switch op {
+		case "+":
+			return numResult(l.Num+r.Num, t)
+		case "-":
+			return numResult(l.Num-r.Num, t)
+		case "*":
+			return numResult(l.Num*r.Num, t)
+		case "/":
+			if r.Num == 0 {
+				return Value{}, newError(ErrDivisionByZero, "division by zero")
+			}
+			if t == Integer {
+				t = Single
+			}
+			return numResult(l.Num/r.Num, t)
+		case "\\":
+			return intDiv(l, r)
+		}
+	case "=", "<>":
+		// number-number or string-string
+		if l.Kind != r.Kind {
+			return Value{}, newError(ErrTypeMismatch, "type mismatch in comparison")
+		}
+		var ok bool
+		if l.Kind == ValNumber {
+			ok = (l.Num == r.Num)
+		} else {
+			ok = (l.Str == r.Str)
+		}
+		if op == "<>" {
+			ok = !ok
+		}
+		if ok {
+			return NumberValue(1), nil
+		}
+		return NumberValue(0), nil
+
+	case "<", "<=", ">", ">=":
+		if l.Kind != ValNumber || r.Kind != ValNumber {
+			return Value{}, newError(ErrTypeMismatch, "ordered comparison requires numbers")
+		}
+		var ok bool
+		switch op {
+		case "<":
+			ok = l.Num < r.Num
+		case "<=":
+			ok = l.Num <= r.Num
+		case ">":
+			ok = l.Num > r.Num
+		case ">=":
+			ok = l.Num >= r.Num
+		}
+		if ok {
+			return NumberValue(1), nil
+		}
+		return NumberValue(0), nil
+	}
+	return Value{}, newError(ErrSyntax, "unsupported operator %q", op)
+}
@@ -0,0 +1,320 @@
+/**************************************************************/
+/*
+   snapshot.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+)
+
+// SnapshotVersion is the version of the snapshot format written by this
+// package. ReadSnapshot rejects other versions.
+const SnapshotVersion = 1
+
+// Snapshot is the state of a paused run, written as JSON by SNAPSHOT and
+// Snapshot.Write:
+//
+//	{
+//	  "version": 1,
+//	  "source": "10 A = 1\n20 GOSUB 100\n...",
+//	  "pc": 2,
+//	  "stack": [2],
+//	  "vars": [{"name": "A", "num": 1}, {"name": "N$", "str": "x"}],
+//	  "arrays": [{"name": "S", "dims": [10], "vals": [{}, {"num": 2}, ...]}],
+//	  "rand": "<base64 rand.PCG state>",
+//	  "rnd": 0.25,
+//	  "decimal": {"scale": 20, "rounding": "half-even"},
+//	  "errCode": 0, "errLine": 0, "onError": -1, "inHandler": false
+//	}
+//
+// Statements are numbered from 0 in line order. Open files, limits and
+// the settings of the Interpreter are not saved.
+type Snapshot struct {
+	Version int             `json:"version"`
+	Source  string          `json:"source"` // program listing
+	PC      int             `json:"pc"`     // index of the statement to run next
+	Stack   []int           `json:"stack"`  // GOSUB return indices
+	Vars    []SnapshotVar   `json:"vars"`
+	Arrays  []SnapshotArray `json:"arrays"`
+
+	Rand    []byte          `json:"rand"` // state of the RND generator
+	Rnd     float64         `json:"rnd"`  // last RND value
+	Decimal *DecimalOptions `json:"decimal,omitempty"`
+
+	ErrCode   int            `json:"errCode"`
+	ErrLine   int            `json:"errLine"`
+	OnError   int            `json:"onError"` // index of the handler, -1: trapping off
+	InHandler bool           `json:"inHandler"`
+	ErrPC     int            `json:"errPC,omitempty"`
+	Pending   *SnapshotError `json:"pending,omitempty"` // error being handled
+}
+
+// SnapshotValue is the value of a variable or an array element. Its kind
+// and type follow from the name.
+type SnapshotValue struct {
+	Num float64  `json:"num,omitempty"`
+	Str string   `json:"str,omitempty"`
+	Dec *big.Rat `json:"dec,omitempty"` // exact value in decimal mode
+}
+
+type SnapshotVar struct {
+	Name string `json:"name"`
+	SnapshotValue
+}
+
+type SnapshotArray struct {
+	Name string          `json:"name"`
+	Dims []int           `json:"dims"` // upper bounds
+	Vals []SnapshotValue `json:"vals"` // in row-major order
+}
+
+type SnapshotError struct {
+	Code int    `json:"code"`
+	Line int    `json:"line"`
+	Stmt string `json:"stmt"`
+	Msg  string `json:"msg"`
+}
+
+// ReadSnapshot decodes a snapshot written by Snapshot.Write.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot: unsupported version %d", s.Version)
+	}
+	return &s, nil
+}
+
+// Write encodes s as JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Snapshot returns the state of the last run. After Run stops with the
+// error of a cancelled context the snapshot resumes at the statement that
+// was about to run.
+func (it *Interpreter) Snapshot() (*Snapshot, error) {
+	return it.snapshot(it.pc)
+}
+
+// Resume restores snap into it, replacing its program and variables, and
+// continues the run as Run does.
+func (it *Interpreter) Resume(ctx context.Context, snap *Snapshot) error {
+	lp, err := it.restore(snap)
+	if err != nil {
+		return err
+	}
+	return it.run(ctx, lp, snap.PC)
+}
+
+// snapshot returns the state of the run to resume at index pc.
+func (it *Interpreter) snapshot(pc int) (*Snapshot, error) {
+	var src strings.Builder
+	for _, line := range it.Prog.OrderedLines() {
+		fmt.Fprintf(&src, "%d %s\n", line, it.Prog.Source[line])
+	}
+	s := &Snapshot{
+		Version:   SnapshotVersion,
+		Source:    src.String(),
+		PC:        pc,
+		Stack:     append([]int{}, it.stack...),
+		Vars:      []SnapshotVar{},
+		Arrays:    []SnapshotArray{},
+		Rnd:       it.rnd,
+		Decimal:   it.dec,
+		ErrCode:   it.errCode,
+		ErrLine:   it.errLine,
+		OnError:   it.onError,
+		InHandler: it.inHandler,
+		ErrPC:     it.errPC,
+	}
+	if it.pcg == nil {
+		it.seed(it.Seed)
+	}
+	var err error
+	if s.Rand, err = it.pcg.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if be := it.pending; be != nil {
+		s.Pending = &SnapshotError{Code: be.Code, Line: be.Line, Stmt: be.Stmt, Msg: be.Msg}
+	}
+	for _, name := range it.Env.Names() {
+		sv, err := snapshotValue(name, it.Env.Get(name))
+		if err != nil {
+			return nil, err
+		}
+		s.Vars = append(s.Vars, SnapshotVar{Name: name, SnapshotValue: sv})
+	}
+	for _, name := range slices.Sorted(maps.Keys(it.Env.arrays)) {
+		a := it.Env.arrays[name]
+		sa := SnapshotArray{Name: name, Dims: a.dims, Vals: make([]SnapshotValue, len(a.vals))}
+		for i, v := range a.vals {
+			if sa.Vals[i], err = snapshotValue(name+"()", v); err != nil {
+				return nil, err
+			}
+		}
+		s.Arrays = append(s.Arrays, sa)
+	}
+	return s, nil
+}
+
+// snapshotValue returns v as saved. JSON has no infinities or NaN, so
+// they are an overflow; the Num of a decimal follows from Dec and is not
+// saved, as it may be infinite.
+func snapshotValue(name string, v Value) (SnapshotValue, error) {
+	switch {
+	case v.Kind == ValString:
+		return SnapshotValue{Str: v.Str}, nil
+	case v.Dec != nil:
+		return SnapshotValue{Dec: v.Dec}, nil
+	case math.IsInf(v.Num, 0) || math.IsNaN(v.Num):
+		return SnapshotValue{}, newError(ErrOverflow, "%s is %v and cannot be saved", name, v.Num)
+	}
+	return SnapshotValue{Num: v.Num}, nil
+}
+
+// value returns sv as a value of the variable name.
+func (sv SnapshotValue) value(e *Env, name string) Value {
+	isStr, typ := e.syms.typeOf(name)
+	switch {
+	case isStr:
+		return StringValue(sv.Str)
+	case sv.Dec != nil:
+		v := decimalValue(sv.Dec)
+		v.Type = typ
+		return v
+	default:
+		return Value{Kind: ValNumber, Num: sv.Num, Type: typ}
+	}
+}
+
+// restore replaces the program and state of it with snap and returns the
+// linked program, ready to run from snap.PC.
+func (it *Interpreter) restore(snap *Snapshot) (*Linked, error) {
+	if snap.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot: unsupported version %d", snap.Version)
+	}
+	prog, err := ParseProgram(strings.NewReader(snap.Source))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	prog.SetOptimize(it.Prog.Optimized())
+	lp, err := prog.Link()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	n := len(lp.Stmts)
+	valid := func(i int) bool { return i >= 0 && i <= n }
+	if !valid(snap.PC) || !valid(snap.ErrPC) || snap.OnError < -1 || snap.OnError >= n {
+		return nil, fmt.Errorf("snapshot: statement index out of range")
+	}
+	for _, ret := range snap.Stack {
+		if !valid(ret) {
+			return nil, fmt.Errorf("snapshot: statement index out of range")
+		}
+	}
+
+	it.closeFiles()
+	it.Prog = prog
+	it.Env = NewEnv()
+	it.Env.bind(prog.syms)
+	it.reset()
+	for _, sv := range snap.Vars {
+		if err := it.Env.Set(sv.Name, sv.value(it.Env, sv.Name)); err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+	}
+	for _, sa := range snap.Arrays {
+		a := it.Env.setArray(sa.Name, sa.Dims)
+		if len(sa.Vals) != len(a.vals) {
+			return nil, fmt.Errorf("snapshot: array %s has %d values, want %d", sa.Name, len(sa.Vals), len(a.vals))
+		}
+		for i, sv := range sa.Vals {
+			a.vals[i] = sv.value(it.Env, sa.Name)
+		}
+	}
+	if err := it.pcg.UnmarshalBinary(snap.Rand); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	it.rnd = snap.Rnd
+	it.dec = snap.Decimal
+	it.stack = append(it.stack[:0], snap.Stack...)
+	it.errCode, it.errLine = snap.ErrCode, snap.ErrLine
+	it.onError, it.inHandler, it.errPC = snap.OnError, snap.InHandler, snap.ErrPC
+	it.pending = nil
+	if p := snap.Pending; p != nil {
+		it.pending = &BasicError{Code: p.Code, Line: p.Line, Stmt: p.Stmt, Msg: p.Msg}
+	}
+	return lp, nil
+}
+
+// resumeSnapshot is returned by RESUME SNAPSHOT to make run switch to the
+// snapshot.
+type resumeSnapshot struct {
+	snap *Snapshot
+}
+
+func (r *resumeSnapshot) Error() string { return "RESUME SNAPSHOT" }
+
+// snapshotStmt runs SNAPSHOT and RESUME SNAPSHOT; pc is the index of the
+// statement.
+func (it *Interpreter) snapshotStmt(s *SnapshotStmt, pc int) error {
+	v, err := it.evalExpr(s.Name)
+	if err != nil {
+		return err
+	}
+	if v.Kind != ValString {
+		return newError(ErrTypeMismatch, "%s requires a file name", s.keyword())
+	}
+	if it.FS == nil {
+		return newError(ErrPathAccess, "no file system")
+	}
+
+	if s.Resume {
+		f, err := it.FS.Open(v.Str)
+		if err != nil {
+			return fileError(err)
+		}
+		defer f.Close()
+		snap, err := ReadSnapshot(f)
+		if err != nil {
+			return newError(ErrIllegalFunction, "%v", err)
+		}
+		return &resumeSnapshot{snap: snap}
+	}
+
+	if len(it.files) > 0 {
+		return newError(ErrIllegalFunction, "SNAPSHOT with open files")
+	}
+	snap, err := it.snapshot(pc + 1)
+	if err != nil {
+		return err
+	}
+	f, err := it.FS.OpenFile(v.Str, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return fileError(err)
+	}
+	if err := snap.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
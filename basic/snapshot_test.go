@@ -0,0 +1,204 @@
+/**************************************************************/
+/*
+   snapshot_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// snapshotPrograms keep state in every place a snapshot saves: variables
+// of each type, arrays, the GOSUB stack, RND, decimal mode and the ON
+// ERROR state.
+var snapshotPrograms = []struct {
+	name string
+	src  string
+	opts []Option
+}{
+	{"Variables", `10 A = 1
+20 B% = 2
+30 C! = 0.5
+40 N$ = "X"
+50 A = A * 3 + B%
+60 C! = C! / 2
+70 N$ = "Y"
+80 IF A < 1000 THEN 50
+90 PRINT A, B%, C!, N$
+`, nil},
+	{"Gosub", `10 N = 0
+20 GOSUB 100
+30 PRINT "BACK", N
+40 END
+100 N = N + 1
+110 IF N < 5 THEN GOSUB 100
+120 PRINT "RETURN", N
+130 RETURN
+`, nil},
+	{"Arrays", `10 DIM A(4), S$(1, 1)
+20 I = 0
+30 A(I) = I * I
+40 I = I + 1
+50 IF I <= 4 THEN 30
+60 S$(1, 0) = "P"
+65 B(3) = 9
+70 PRINT A(0) + A(1) + A(2) + A(3) + A(4), S$(1, 0), B(3)
+`, nil},
+	{"Random", `10 RANDOMIZE 7
+20 I = 0
+30 PRINT RND(1)
+40 I = I + 1
+50 IF I < 6 THEN 30
+`, nil},
+	{"Decimal", `10 A = 0
+20 I = 0
+30 A = A + 0.1
+40 I = I + 1
+50 IF I < 10 THEN 30
+60 PRINT A, A = 1, 1 / 3
+`, []Option{WithDecimal(DefaultDecimal)}},
+	{"OnError", `10 ON ERROR GOTO 100
+20 A = 1 / 0
+30 PRINT "AFTER", ERR, ERL
+40 END
+100 PRINT "HANDLER", ERR
+110 X = 1
+120 X = X + 1
+130 RESUME NEXT
+`, nil},
+}
+
+// TestSnapshotResume stops each program after every possible number of
+// statements, resumes it from a snapshot in a new interpreter and checks
+// that the output adds up to that of an uninterrupted run.
+func TestSnapshotResume(t *testing.T) {
+	for _, p := range snapshotPrograms {
+		for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+			opts := append([]Option{WithEngine(engine)}, p.opts...)
+			var full bytes.Buffer
+			it := NewInterpreter(append(opts, WithProgram(parseSource(t, p.src)), WithStdout(&full))...)
+			if err := it.Run(context.Background()); err != nil {
+				t.Fatalf("%s on engine %d: %v", p.name, engine, err)
+			}
+			want, total := full.String(), it.ops
+			for stop := 1; stop < total; stop++ {
+				var out bytes.Buffer
+				it := NewInterpreter(append(opts, WithProgram(parseSource(t, p.src)), WithStdout(&out), WithMaxOps(stop))...)
+				if err := it.Run(context.Background()); err == nil {
+					t.Fatalf("%s on engine %d: not stopped after %d statements", p.name, engine, stop)
+				}
+				snap, err := it.Snapshot()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var buf bytes.Buffer
+				if err := snap.Write(&buf); err != nil {
+					t.Fatal(err)
+				}
+				if snap, err = ReadSnapshot(&buf); err != nil {
+					t.Fatal(err)
+				}
+				it = NewInterpreter(append(opts, WithStdout(&out), WithMaxOps(0))...)
+				if err := it.Resume(context.Background(), snap); err != nil {
+					fmt.Fprintln(&out, err)
+				}
+				if got := out.String(); got != want {
+					t.Errorf("%s on engine %d stopped after %d statements: got\n%s\nwant\n%s", p.name, engine, stop, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSnapshotStatements(t *testing.T) {
+	save := `10 A = 5
+20 DIM L(2)
+30 L(2) = 7
+40 GOSUB 100
+50 PRINT "AFTER", A, L(2)
+60 END
+100 SNAPSHOT "S.JSON"
+110 A = A + 1
+120 RETURN
+`
+	resume := `10 RESUME SNAPSHOT "S.JSON"
+`
+	for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+		fsys := NewMemFS()
+		if got, want := runWith(parseSource(t, save), WithFS(fsys), WithEngine(engine)), "AFTER  6  7\n"; got != want {
+			t.Errorf("engine %d: SNAPSHOT run printed\n%s\nwant\n%s", engine, got, want)
+		}
+		// the resumed run continues after SNAPSHOT, in the saved program
+		if got, want := runWith(parseSource(t, resume), WithFS(fsys), WithEngine(engine)), "AFTER  6  7\n"; got != want {
+			t.Errorf("engine %d: RESUME SNAPSHOT printed\n%s\nwant\n%s", engine, got, want)
+		}
+	}
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	if _, err := ReadSnapshot(bytes.NewReader([]byte(`{"version": 99}`))); err == nil {
+		t.Error("ReadSnapshot accepted version 99")
+	}
+}
+
+func TestSnapshotNotFinite(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		src   string
+	}{
+		{"Inf", "Inf\n", `20 INPUT A
+`},
+		{"NaN", "NaN\n", `20 INPUT N
+25 B(1) = N
+`},
+	}
+	for _, tt := range tests {
+		for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+			fsys := NewMemFS()
+			fsys.WriteFile("S.JSON", []byte("OLD"))
+			src := "10 ON ERROR GOTO 100\n" + tt.src + "30 SNAPSHOT \"S.JSON\"\n40 END\n100 PRINT ERR, ERL\n110 RESUME NEXT\n"
+			got := runWith(parseSource(t, src), WithFS(fsys), WithEngine(engine), WithStdin(strings.NewReader(tt.stdin)))
+			if want := "?  6  30\n"; got != want {
+				t.Errorf("%s on engine %d: got\n%s\nwant\n%s", tt.name, engine, got, want)
+			}
+			if data, _ := fs.ReadFile(fsys, "S.JSON"); string(data) != "OLD" {
+				t.Errorf("%s on engine %d: S.JSON is %q, want it unchanged", tt.name, engine, data)
+			}
+		}
+	}
+}
+
+// TestSnapshotLargeDecimal saves a decimal too large for a float64.
+func TestSnapshotLargeDecimal(t *testing.T) {
+	src := `10 OPTION DECIMAL
+20 A = 1
+30 A = A * 10000000000
+40 I = I + 1
+50 IF I < 40 THEN 30
+60 SNAPSHOT "S.JSON"
+70 A = A / 10000000000
+80 I = I - 1
+90 IF I > 0 THEN 70
+100 PRINT A
+`
+	for _, engine := range []Engine{EngineTree, EngineVM, EngineClosure} {
+		fsys := NewMemFS()
+		if got, want := runWith(parseSource(t, src), WithFS(fsys), WithEngine(engine)), " 1\n"; got != want {
+			t.Errorf("engine %d: SNAPSHOT run printed\n%s\nwant\n%s", engine, got, want)
+		}
+		resume := parseSource(t, "10 RESUME SNAPSHOT \"S.JSON\"\n")
+		if got, want := runWith(resume, WithFS(fsys), WithEngine(engine)), " 1\n"; got != want {
+			t.Errorf("engine %d: RESUME SNAPSHOT printed\n%s\nwant\n%s", engine, got, want)
+		}
+	}
+}
@@ -0,0 +1,669 @@
+/**************************************************************/
+/*
+   transpile.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Transpile translates prog into the source of a standalone Go program.
+// Each line becomes a method of a machine type and a dispatch loop
+// switches between them, so GOTO is an assignment of the next line.
+// The generated runtime follows Value semantics and reproduces the
+// interpreter's output and error messages, including MaxOps.
+func Transpile(prog *Program, name string) (string, error) {
+	lp, err := prog.Link()
+	if err != nil {
+		return "", err
+	}
+
+	g := &transpiler{lp: lp, vars: map[string]bool{}}
+	var body strings.Builder
+	for i, stmt := range prog.OrderedLines() {
+		g.sb.Reset()
+		g.temps = 0
+		g.pc = i
+		if err := g.stmt(prog.Stmts[stmt]); err != nil {
+			return "", fmt.Errorf("line %d: %w", stmt, err)
+		}
+		fmt.Fprintf(&body, "\n// %d %s\nfunc (m *machine) %s() (int, error) {\n%s}\n",
+			stmt, prog.Source[stmt], g.label(i), g.sb.String())
+	}
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "// Code generated by basic build from %s. DO NOT EDIT.\n\n", name)
+	src.WriteString(transpileHeader)
+
+	src.WriteString("\nconst (\n")
+	for i := range lp.Lines {
+		fmt.Fprintf(&src, "\t%s = %d\n", g.label(i), i)
+	}
+	fmt.Fprintf(&src, "\tdone = %d // ran past the last line\n)\n", len(lp.Lines))
+
+	src.WriteString("\nvar lineNumbers = []int{")
+	for i, ln := range lp.Lines {
+		if i > 0 {
+			src.WriteString(", ")
+		}
+		src.WriteString(strconv.Itoa(ln))
+	}
+	src.WriteString("}\n")
+
+	src.WriteString("\ntype machine struct {\n\truntime\n")
+	for _, v := range sortedKeys(g.vars) {
+		if strings.HasSuffix(v, "$") {
+			fmt.Fprintf(&src, "\t%s string\n", goVarName(v))
+		} else {
+			fmt.Fprintf(&src, "\t%s float64\n", goVarName(v))
+		}
+	}
+	src.WriteString("}\n")
+
+	src.WriteString("\nfunc (m *machine) exec(pc int) (int, error) {\n\tswitch pc {\n")
+	for i := range lp.Lines {
+		fmt.Fprintf(&src, "\tcase %s:\n\t\treturn m.%s()\n", g.label(i), g.label(i))
+	}
+	src.WriteString("\t}\n\tpanic(\"bad line index\")\n}\n")
+	src.WriteString(body.String())
+	src.WriteString(transpileRuntime)
+
+	out, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return "", fmt.Errorf("generated code does not format: %w", err)
+	}
+	return string(out), nil
+}
+
+type transpiler struct {
+	lp    *Linked
+	pc    int // index of the line being generated
+	sb    strings.Builder
+	temps int
+	vars  map[string]bool
+}
+
+func (g *transpiler) label(i int) string {
+	return fmt.Sprintf("line%d", g.lp.Lines[i])
+}
+
+// next returns the label of the line after the current one.
+func (g *transpiler) next() string {
+	if g.pc+1 < len(g.lp.Lines) {
+		return g.label(g.pc + 1)
+	}
+	return "done"
+}
+
+// target returns the label of the current line's jump target.
+func (g *transpiler) target() string {
+	return g.label(g.lp.Target[g.pc])
+}
+
+func (g *transpiler) emit(format string, a ...any) {
+	g.sb.WriteString("\t")
+	fmt.Fprintf(&g.sb, format, a...)
+	g.sb.WriteString("\n")
+}
+
+func (g *transpiler) temp() string {
+	g.temps++
+	return fmt.Sprintf("t%d", g.temps)
+}
+
+func (g *transpiler) stmt(stmt Stmt) error {
+	switch s := stmt.(type) {
+	case *RemStmt:
+		g.emit("return %s, nil", g.next())
+
+	case *LetStmt:
+		if s.Index != nil {
+			return fmt.Errorf("array %s is not supported by build", s.Name)
+		}
+		if err := checkVarName(s.Name); err != nil {
+			return err
+		}
+		v, err := g.expr(s.Expr)
+		if err != nil {
+			return err
+		}
+		g.store(s.Name, v)
+		g.emit("return %s, nil", g.next())
+
+	case *PrintStmt:
+		if s.Using != nil {
+			return fmt.Errorf("PRINT USING is not supported by build")
+		}
+		if s.File != nil {
+			return fmt.Errorf("PRINT # is not supported by build")
+		}
+		vals := make([]string, 0, len(s.Exprs))
+		for _, e := range s.Exprs {
+			v, err := g.expr(e)
+			if err != nil {
+				return err
+			}
+			vals = append(vals, v)
+		}
+		g.emit("return %s, m.print(%s)", g.next(), strings.Join(vals, ", "))
+
+	case *InputStmt:
+		if err := checkVarName(s.Name); err != nil {
+			return err
+		}
+		t := g.temp()
+		g.emit("%s, err := m.input(%t)", t, strings.HasSuffix(s.Name, "$"))
+		g.check()
+		g.store(s.Name, t)
+		g.emit("return %s, nil", g.next())
+
+	case *IfStmt:
+		c, err := g.expr(s.Cond)
+		if err != nil {
+			return err
+		}
+		g.emit("if %s.kind != kindNum {", c)
+		g.emit("\treturn 0, basicError(13, \"IF condition must be numeric\")")
+		g.emit("}")
+		g.emit("if %s.num == 0 {", c)
+		g.emit("\treturn %s, nil", g.next())
+		g.emit("}")
+		if s.HasLine {
+			g.emit("return %s, nil", g.target())
+			break
+		}
+		return g.stmt(s.ThenStmt)
+
+	case *GotoStmt:
+		g.emit("return %s, nil", g.target())
+
+	case *GosubStmt:
+		g.emit("m.stack = append(m.stack, %s)", g.next())
+		g.emit("return %s, nil", g.target())
+
+	case *ReturnStmt:
+		g.emit("return m.ret()")
+
+	case *OnErrorStmt:
+		target := "-1"
+		if s.Line != 0 {
+			target = g.target()
+		}
+		g.emit("return %s, m.setOnError(%s)", g.next(), target)
+
+	case *ResumeStmt:
+		switch {
+		case s.Next:
+			g.emit("return m.resume(resumeNext, 0)")
+		case s.Line != 0:
+			g.emit("return m.resume(resumeLine, %s)", g.target())
+		default:
+			g.emit("return m.resume(resumeSame, 0)")
+		}
+
+	case *ErrorStmt:
+		v, err := g.expr(s.Code)
+		if err != nil {
+			return err
+		}
+		g.emit("return 0, raiseError(%s)", v)
+
+	case *EndStmt:
+		g.emit("return stop, nil")
+
+	case *ExtStmt:
+		return fmt.Errorf("statement %s is not supported by build", s.Keyword)
+
+	default:
+		return fmt.Errorf("%T is not supported by build", stmt)
+	}
+	return nil
+}
+
+func (g *transpiler) check() {
+	g.emit("if err != nil {")
+	g.emit("\treturn 0, err")
+	g.emit("}")
+}
+
+func (g *transpiler) store(name, v string) {
+	g.vars[name] = true
+	if strings.HasSuffix(name, "$") {
+		g.emit("if err := storeStr(&m.%s, %q, %s); err != nil {", goVarName(name), name, v)
+	} else {
+		g.emit("if err := storeNum(&m.%s, %q, %s); err != nil {", goVarName(name), name, v)
+	}
+	g.emit("\treturn 0, err")
+	g.emit("}")
+}
+
+// expr emits the statements needed to evaluate e and returns a Go
+// expression of type value holding the result.
+func (g *transpiler) expr(e Expr) (string, error) {
+	switch x := e.(type) {
+	case *NumberLit:
+		return "num(" + strconv.FormatFloat(x.Value, 'g', -1, 64) + ")", nil
+	case *StringLit:
+		return "str(" + strconv.Quote(x.Value) + ")", nil
+	case *VarRef:
+		if err := checkVarName(x.Name); err != nil {
+			return "", err
+		}
+		g.vars[x.Name] = true
+		if strings.HasSuffix(x.Name, "$") {
+			return "str(m." + goVarName(x.Name) + ")", nil
+		}
+		return "num(m." + goVarName(x.Name) + ")", nil
+	case *FuncExpr:
+		switch x.Name {
+		case "ERR":
+			return "num(float64(m.errCode))", nil
+		case "ERL":
+			return "num(float64(m.errLine))", nil
+		}
+		return "", fmt.Errorf("function %s is not supported by build", x.Name)
+	case *UnaryExpr:
+		rhs, err := g.expr(x.Rhs)
+		if err != nil {
+			return "", err
+		}
+		t := g.temp()
+		g.emit("%s, err := unary(%q, %s)", t, x.Op, rhs)
+		g.check()
+		return t, nil
+	case *BinaryExpr:
+		if x.Op == "\\" {
+			return "", fmt.Errorf("operator \\ is not supported by build")
+		}
+		lhs, err := g.expr(x.Lhs)
+		if err != nil {
+			return "", err
+		}
+		rhs, err := g.expr(x.Rhs)
+		if err != nil {
+			return "", err
+		}
+		t := g.temp()
+		g.emit("%s, err := binary(%q, %s, %s)", t, x.Op, lhs, rhs)
+		g.check()
+		return t, nil
+	}
+	return "", fmt.Errorf("%T is not supported by build", e)
+}
+
+// checkVarName rejects typed variables; the generated code only has
+// doubles and strings.
+func checkVarName(name string) error {
+	if strings.ContainsAny(name, "%!#") {
+		return fmt.Errorf("typed variable %s is not supported by build", name)
+	}
+	return nil
+}
+
+// goVarName maps a BASIC variable to a field of the generated machine.
+func goVarName(name string) string {
+	if base, ok := strings.CutSuffix(name, "$"); ok {
+		return "str" + base
+	}
+	return "var" + name
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const transpileHeader = `package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	m := &machine{}
+	m.in = bufio.NewReader(os.Stdin)
+	m.out = os.Stdout
+	m.dispatch = m.exec
+	if err := m.run(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+const stop = -1 // END
+`
+
+// transpileRuntime is the part of the generated program that mirrors
+// runtime.go and errors.go. Keep the messages in step with them.
+const transpileRuntime = `
+const maxOps = 1_000_000
+
+type kind int
+
+const (
+	kindNum kind = iota
+	kindStr
+)
+
+type value struct {
+	kind kind
+	num  float64
+	str  string
+}
+
+func num(n float64) value { return value{kind: kindNum, num: n} }
+func str(s string) value  { return value{kind: kindStr, str: s} }
+
+func (v value) String() string {
+	if v.kind == kindNum {
+		return strconv.FormatFloat(v.num, 'g', -1, 64)
+	}
+	return v.str
+}
+
+// format formats v for PRINT like classicNumber does for doubles.
+func (v value) format() string {
+	if v.kind != kindNum {
+		return v.str
+	}
+	n := v.num
+	if math.IsInf(n, 0) || math.IsNaN(n) {
+		return v.String()
+	}
+	sign := " "
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	if n == 0 {
+		return " 0"
+	}
+	const digits = 16
+	s := strconv.FormatFloat(n, 'e', -1, 64)
+	if len(s) > digits+5 {
+		s = strconv.FormatFloat(n, 'e', digits-1, 64)
+	}
+	mant, exp, _ := strings.Cut(s, "e")
+	e, _ := strconv.Atoi(exp)
+	d := strings.TrimRight(strings.Replace(mant, ".", "", 1), "0")
+	switch {
+	case e >= digits || (e < 0 && -e-1+len(d) > digits):
+		s := sign + d[:1]
+		if len(d) > 1 {
+			s += "." + d[1:]
+		}
+		return s + fmt.Sprintf("E%+03d", e)
+	case e < 0:
+		return sign + "." + strings.Repeat("0", -e-1) + d
+	case len(d) <= e+1:
+		return sign + d + strings.Repeat("0", e+1-len(d))
+	}
+	return sign + d[:e+1] + "." + d[e+1:]
+}
+
+type basicErr struct {
+	code int
+	line int
+	msg  string
+}
+
+func (e *basicErr) Error() string {
+	if e.line > 0 {
+		return fmt.Sprintf("runtime error at line %d: %s", e.line, e.msg)
+	}
+	return "runtime error: " + e.msg
+}
+
+func basicError(code int, format string, a ...any) error {
+	return &basicErr{code: code, msg: fmt.Sprintf(format, a...)}
+}
+
+var errorMessages = map[int]string{
+	2:  "syntax error",
+	3:  "RETURN without GOSUB",
+	4:  "out of DATA",
+	5:  "illegal function call",
+	6:  "overflow",
+	7:  "out of memory",
+	8:  "undefined line number",
+	11: "division by zero",
+	13: "type mismatch",
+	15: "string too long",
+	19: "no RESUME",
+	20: "RESUME without error",
+}
+
+func raiseError(v value) error {
+	if v.kind != kindNum {
+		return basicError(13, "ERROR requires number")
+	}
+	code := int(v.num)
+	if code < 1 || code > 255 {
+		return basicError(5, "illegal function call")
+	}
+	msg, ok := errorMessages[code]
+	if !ok {
+		msg = "unprintable error"
+	}
+	return basicError(code, "%s", msg)
+}
+
+func storeNum(p *float64, name string, v value) error {
+	if v.kind != kindNum {
+		return basicError(13, "type mismatch: %s is numeric variable", name)
+	}
+	*p = v.num
+	return nil
+}
+
+func storeStr(p *string, name string, v value) error {
+	if v.kind != kindStr {
+		return basicError(13, "type mismatch: %s is string variable", name)
+	}
+	*p = v.str
+	return nil
+}
+
+func unary(op string, v value) (value, error) {
+	if v.kind != kindNum {
+		return value{}, basicError(13, "unary %s requires number", op)
+	}
+	if op == "-" {
+		return num(-v.num), nil
+	}
+	return v, nil
+}
+
+func truth(ok bool) value {
+	if ok {
+		return num(1)
+	}
+	return num(0)
+}
+
+func binary(op string, l, r value) (value, error) {
+	switch op {
+	case "+", "-", "*", "/":
+		if l.kind != kindNum || r.kind != kindNum {
+			return value{}, basicError(13, "arithmetic requires numbers")
+		}
+		switch op {
+		case "+":
+			return num(l.num + r.num), nil
+		case "-":
+			return num(l.num - r.num), nil
+		case "*":
+			return num(l.num * r.num), nil
+		}
+		if r.num == 0 {
+			return value{}, basicError(11, "division by zero")
+		}
+		return num(l.num / r.num), nil
+	case "=", "<>":
+		if l.kind != r.kind {
+			return value{}, basicError(13, "type mismatch in comparison")
+		}
+		ok := l.num == r.num
+		if l.kind == kindStr {
+			ok = l.str == r.str
+		}
+		return truth(ok == (op == "=")), nil
+	}
+	if l.kind != kindNum || r.kind != kindNum {
+		return value{}, basicError(13, "ordered comparison requires numbers")
+	}
+	switch op {
+	case "<":
+		return truth(l.num < r.num), nil
+	case "<=":
+		return truth(l.num <= r.num), nil
+	case ">":
+		return truth(l.num > r.num), nil
+	}
+	return truth(l.num >= r.num), nil
+}
+
+const (
+	resumeSame = iota
+	resumeNext
+	resumeLine
+)
+
+type runtime struct {
+	in    *bufio.Reader
+	out   io.Writer
+	dispatch func(pc int) (int, error)
+	ops   int
+	stack []int
+
+	errCode   int
+	errLine   int
+	onError   int
+	inHandler bool
+	errPC     int
+	pending   error
+}
+
+func (m *runtime) run() error {
+	m.onError = -1
+	pc := 0
+	for pc >= 0 && pc < done {
+		m.ops++
+		if m.ops > maxOps {
+			return fmt.Errorf("runtime error: operation limit exceeded (possible infinite loop)")
+		}
+		next, err := m.dispatch(pc)
+		if err != nil {
+			if next, err = m.trap(err, pc); err != nil {
+				return err
+			}
+		}
+		if next == stop {
+			return nil
+		}
+		pc = next
+	}
+	if m.inHandler {
+		return &basicErr{code: 19, msg: "no RESUME"}
+	}
+	return nil
+}
+
+func (m *runtime) trap(err error, pc int) (int, error) {
+	var be *basicErr
+	if !errors.As(err, &be) {
+		be = &basicErr{msg: err.Error()}
+	}
+	if be.line == 0 {
+		be.line = lineNumbers[pc]
+	}
+	m.errCode, m.errLine = be.code, be.line
+	if m.onError < 0 || m.inHandler || be.code == 0 {
+		return 0, be
+	}
+	m.inHandler = true
+	m.errPC = pc
+	m.pending = be
+	return m.onError, nil
+}
+
+func (m *runtime) print(vals ...value) error {
+	parts := make([]string, 0, len(vals))
+	for _, v := range vals {
+		parts = append(parts, v.format())
+	}
+	_, err := fmt.Fprintln(m.out, strings.Join(parts, " "))
+	return err
+}
+
+func (m *runtime) input(isStr bool) (value, error) {
+	if _, err := fmt.Fprint(m.out, "? "); err != nil {
+		return value{}, err
+	}
+	line, err := m.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return value{}, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if isStr {
+		return str(line), nil
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+	if err != nil {
+		return value{}, basicError(13, "INPUT expects number")
+	}
+	return num(n), nil
+}
+
+func (m *runtime) ret() (int, error) {
+	if len(m.stack) == 0 {
+		return 0, basicError(3, "RETURN without GOSUB")
+	}
+	ret := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return ret, nil
+}
+
+func (m *runtime) setOnError(idx int) error {
+	if idx < 0 && m.inHandler {
+		return m.pending
+	}
+	m.onError = idx
+	return nil
+}
+
+func (m *runtime) resume(mode, target int) (int, error) {
+	if !m.inHandler {
+		return 0, basicError(20, "RESUME without error")
+	}
+	switch mode {
+	case resumeSame:
+		target = m.errPC
+	case resumeNext:
+		target = m.errPC + 1
+	}
+	m.inHandler = false
+	m.pending = nil
+	return target, nil
+}
+`
@@ -0,0 +1,151 @@
+/**************************************************************/
+/*
+   types.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"math"
+	"math/big"
+)
+
+// NumType is the precision of a number. The zero value is Double, so
+// numbers without a declared type keep float64 precision.
+type NumType uint8
+
+const (
+	Double  NumType = iota // float64, suffix #
+	Single                 // float32, suffix !
+	Integer                // int16, suffix %
+)
+
+// rank orders the types for arithmetic: the result of an operator has
+// the type of its wider operand.
+func (t NumType) rank() int {
+	switch t {
+	case Integer:
+		return 0
+	case Single:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func wider(a, b NumType) NumType {
+	if a.rank() >= b.rank() {
+		return a
+	}
+	return b
+}
+
+// literal returns the value of a numeric literal.
+func literal(n float64) Value {
+	return Value{Kind: ValNumber, Num: n, lit: true}
+}
+
+// arithType returns the type of an arithmetic result, that of the wider
+// operand. A literal takes the type of the other operand, like an untyped
+// constant, so 3 * A! stays single and A% + 1 stays integer while
+// untyped programs keep computing in double. A literal with a fraction
+// is at least single, so C% * 0.5 is 2.5.
+func arithType(l, r Value) NumType {
+	switch {
+	case l.lit && !r.lit:
+		return litType(l, r.Type)
+	case r.lit && !l.lit:
+		return litType(r, l.Type)
+	}
+	return wider(l.Type, r.Type)
+}
+
+// litType returns the type the literal v takes next to an operand of
+// type t.
+func litType(v Value, t NumType) NumType {
+	if t == Integer && v.Num != math.Trunc(v.Num) {
+		return Single
+	}
+	return t
+}
+
+// suffixType returns the type given by the suffix of a variable name.
+func suffixType(name string) (isStr bool, t NumType, ok bool) {
+	if name == "" {
+		return false, Double, false
+	}
+	switch name[len(name)-1] {
+	case '$':
+		return true, Double, true
+	case '%':
+		return false, Integer, true
+	case '!':
+		return false, Single, true
+	case '#':
+		return false, Double, true
+	}
+	return false, Double, false
+}
+
+// toType converts the number v to t. Integers are rounded half to even,
+// as CINT does; a value out of the range of t is an overflow.
+func toType(v Value, t NumType) (Value, error) {
+	n := v.Num
+	switch t {
+	case Double:
+		return Value{Kind: ValNumber, Num: n, Dec: v.Dec}, nil
+	case Integer:
+		n = math.RoundToEven(n)
+		if !(n >= math.MinInt16 && n <= math.MaxInt16) {
+			return Value{}, newError(ErrOverflow, "overflow")
+		}
+	case Single:
+		f := float32(n)
+		if math.IsInf(float64(f), 0) || math.IsNaN(n) {
+			return Value{}, newError(ErrOverflow, "overflow")
+		}
+		n = float64(f)
+	}
+	return Value{Kind: ValNumber, Num: n, Type: t}, nil
+}
+
+// numResult returns the result n of an arithmetic operator of type t.
+// Double results are not checked, as before typed variables existed.
+func numResult(n float64, t NumType) (Value, error) {
+	if t == Double {
+		return NumberValue(n), nil
+	}
+	return toType(NumberValue(n), t)
+}
+
+// intDiv implements l \ r: both operands are rounded to integers and the
+// quotient is truncated.
+func intDiv(l, r Value) (Value, error) {
+	a, err := toType(l, Integer)
+	if err != nil {
+		return Value{}, err
+	}
+	b, err := toType(r, Integer)
+	if err != nil {
+		return Value{}, err
+	}
+	if b.Num == 0 {
+		return Value{}, newError(ErrDivisionByZero, "division by zero")
+	}
+	return numResult(math.Trunc(a.Num/b.Num), Integer)
+}
+
+// negate implements unary minus, keeping the type of v.
+func negate(v Value) (Value, error) {
+	if v.Dec != nil {
+		n := decimalValue(new(big.Rat).Neg(v.Dec))
+		n.lit = v.lit
+		return n, nil
+	}
+	n, err := numResult(-v.Num, v.Type)
+	n.lit = v.lit
+	return n, err
+}
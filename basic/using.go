@@ -0,0 +1,305 @@
+/**************************************************************/
+/*
+   using.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// printUsing writes vals formatted by the PRINT USING format to w and ends
+// the line. The format is reused from the start when it runs out of fields.
+func (it *Interpreter) printUsing(w io.Writer, format Value, vals []Value) error {
+	if format.Kind != ValString {
+		return newError(ErrTypeMismatch, "PRINT USING format must be a string")
+	}
+	s, err := formatUsing(format.Str, vals)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, s)
+	return err
+}
+
+// formatUsing formats vals with the PRINT USING format f:
+//
+//	#      digit             !      first character of a string
+//	.      decimal point     &      whole string
+//	,      thousands (before the point)
+//	+      sign, first or last       \  \   2 + spaces characters
+//	-      trailing minus            _      next character as is
+//	$$     floating dollar sign
+//	**     fill with *, **$ with a dollar sign
+//	^^^^   exponent, ^^^^^ for three exponent digits
+//
+// A number too wide for its field is printed in full after a %.
+func formatUsing(f string, vals []Value) (string, error) {
+	var b strings.Builder
+	i, fields := 0, 0
+	for {
+		if i == len(f) {
+			if len(vals) == 0 {
+				break
+			}
+			if fields == 0 {
+				return "", newError(ErrIllegalFunction, "PRINT USING format has no fields")
+			}
+			i = 0
+		}
+		if n, ok := stringField(f, i); ok {
+			if len(vals) == 0 {
+				break
+			}
+			v := vals[0]
+			if v.Kind != ValString {
+				return "", newError(ErrTypeMismatch, "PRINT USING field %q requires string", f[i:i+n])
+			}
+			b.WriteString(usingString(f[i:i+n], v.Str))
+			vals = vals[1:]
+			fields++
+			i += n
+			continue
+		}
+		if nf, n, ok := numberField(f, i); ok {
+			if len(vals) == 0 {
+				break
+			}
+			v := vals[0]
+			if v.Kind != ValNumber {
+				return "", newError(ErrTypeMismatch, "PRINT USING field %q requires number", f[i:i+n])
+			}
+			b.WriteString(nf.format(v))
+			vals = vals[1:]
+			fields++
+			i += n
+			continue
+		}
+		if f[i] == '_' && i+1 < len(f) {
+			i++
+		}
+		b.WriteByte(f[i])
+		i++
+	}
+	return b.String(), nil
+}
+
+// stringField returns the length of the string field at f[i:], if any.
+func stringField(f string, i int) (int, bool) {
+	switch f[i] {
+	case '!', '&':
+		return 1, true
+	case '\\':
+		j := i + 1
+		for j < len(f) && f[j] == ' ' {
+			j++
+		}
+		if j < len(f) && f[j] == '\\' {
+			return j - i + 1, true
+		}
+	}
+	return 0, false
+}
+
+func usingString(field, s string) string {
+	switch field {
+	case "!":
+		if s == "" {
+			return " "
+		}
+		return s[:1]
+	case "&":
+		return s
+	}
+	if len(s) >= len(field) {
+		return s[:len(field)]
+	}
+	return s + strings.Repeat(" ", len(field)-len(s))
+}
+
+// usingField is a numeric field of a PRINT USING format.
+type usingField struct {
+	width    int  // characters before the decimal point, including fill, $ and sign
+	decimals int  // digits after the decimal point
+	point    bool // the field has a decimal point
+	comma    bool // separate thousands with commas
+	fill     byte // ' ', or '*' for **
+	dollar   bool
+	plus     bool // leading sign
+	trail    byte // trailing sign: '+', '-' or 0
+	exp      int  // exponent digits, 0: no exponent
+}
+
+// numberField parses the numeric field at f[i:], if any, and returns it
+// with its length.
+func numberField(f string, i int) (usingField, int, bool) {
+	nf := usingField{fill: ' '}
+	at := func(j int, s string) bool { return strings.HasPrefix(f[j:], s) }
+	j := i
+	if at(j, "+") {
+		nf.plus = true
+		nf.width++
+		j++
+	}
+	switch {
+	case at(j, "**$"):
+		nf.fill, nf.dollar = '*', true
+		nf.width += 3
+		j += 3
+	case at(j, "**"):
+		nf.fill = '*'
+		nf.width += 2
+		j += 2
+	case at(j, "$$"):
+		nf.dollar = true
+		nf.width += 2
+		j += 2
+	}
+	digits := false
+	for j < len(f) && (f[j] == '#' || (f[j] == ',' && digits)) {
+		if f[j] == ',' {
+			nf.comma = true
+		}
+		digits = true
+		nf.width++
+		j++
+	}
+	if at(j, ".") && (digits || at(j+1, "#")) {
+		nf.point = true
+		j++
+		for j < len(f) && f[j] == '#' {
+			nf.decimals++
+			digits = true
+			j++
+		}
+	}
+	if !digits && nf.fill == ' ' && !nf.dollar {
+		return usingField{}, 0, false
+	}
+	switch {
+	case at(j, "^^^^^"):
+		nf.exp = 3
+		j += 5
+	case at(j, "^^^^"):
+		nf.exp = 2
+		j += 4
+	}
+	if !nf.plus && j < len(f) && (f[j] == '+' || f[j] == '-') {
+		nf.trail = f[j]
+		j++
+	}
+	return nf, j - i, true
+}
+
+// format formats the number v in the field.
+func (nf usingField) format(v Value) string {
+	neg := v.Num < 0 || (v.Dec != nil && v.Dec.Sign() < 0)
+	var digits, exp string
+	if nf.exp > 0 {
+		digits, exp = nf.mantissa(math.Abs(v.Num))
+	} else if v.Dec != nil {
+		digits = strings.TrimPrefix(v.Dec.FloatString(nf.decimals), "-")
+	} else {
+		digits = strconv.FormatFloat(math.Abs(v.Num), 'f', nf.decimals, 64)
+	}
+	if strings.Trim(digits, "0.") == "" {
+		neg = false // rounded to zero
+	}
+	intPart, frac, _ := strings.Cut(digits, ".")
+	if nf.comma {
+		intPart = commas(intPart)
+	}
+
+	var sign string
+	switch {
+	case nf.plus && neg:
+		sign = "-"
+	case nf.plus:
+		sign = "+"
+	case nf.trail == 0 && neg:
+		sign = "-"
+	}
+	head := sign
+	if nf.dollar {
+		head += "$"
+	}
+	if intPart == "0" && len(head)+1 > nf.width && nf.point {
+		intPart = ""
+	}
+	head += intPart
+
+	var b strings.Builder
+	if len(head) > nf.width {
+		b.WriteByte('%')
+	} else {
+		b.WriteString(strings.Repeat(string(nf.fill), nf.width-len(head)))
+	}
+	b.WriteString(head)
+	if nf.point {
+		b.WriteString("." + frac)
+	}
+	b.WriteString(exp)
+	switch {
+	case nf.trail == '+' && neg, nf.trail == '-' && neg:
+		b.WriteByte('-')
+	case nf.trail == '+':
+		b.WriteByte('+')
+	case nf.trail == '-':
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// mantissa scales x to the digits of the field and returns it with the
+// exponent, e.g. "12.35" and "E+03" for 12345 in ##.##^^^^. One position
+// is kept for the sign unless the field has a sign of its own.
+func (nf usingField) mantissa(x float64) (string, string) {
+	lead := nf.width
+	if !nf.plus && nf.trail == 0 {
+		lead--
+	}
+	lead = max(lead, 0)
+	e := 0
+	if x != 0 {
+		e = int(math.Floor(math.Log10(x))) - lead + 1
+	}
+	m := strconv.FormatFloat(x/math.Pow(10, float64(e)), 'f', nf.decimals, 64)
+	if ip, _, _ := strings.Cut(m, "."); len(ip) > max(lead, 1) || (lead == 0 && ip != "0") {
+		// rounding carried into a new digit
+		e++
+		m = strconv.FormatFloat(x/math.Pow(10, float64(e)), 'f', nf.decimals, 64)
+	}
+	if lead == 0 {
+		m = strings.TrimPrefix(m, "0")
+	}
+	exp := "E+"
+	if e < 0 {
+		exp = "E-"
+		e = -e
+	}
+	s := strconv.Itoa(e)
+	return m, exp + strings.Repeat("0", max(nf.exp-len(s), 0)) + s
+}
+
+// commas inserts thousands separators into the digits s.
+func commas(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
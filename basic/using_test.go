@@ -0,0 +1,63 @@
+/**************************************************************/
+/*
+   using_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import "testing"
+
+func TestFormatUsing(t *testing.T) {
+	tests := []struct {
+		format string
+		vals   []Value
+		want   string
+	}{
+		{"###.##", []Value{NumberValue(3.14159)}, "  3.14"},
+		{"###.##", []Value{NumberValue(-2.5)}, " -2.50"},
+		{"#,###,###.##", []Value{NumberValue(1234567.891)}, "1,234,567.89"},
+		{"$$###.##", []Value{NumberValue(12.5)}, "  $12.50"},
+		{"**###.##", []Value{NumberValue(12.5)}, "***12.50"},
+		{"**$##.##", []Value{NumberValue(12.5)}, "**$12.50"},
+		{"+###", []Value{NumberValue(12)}, " +12"},
+		{"###-", []Value{NumberValue(-12)}, " 12-"},
+		{"###+", []Value{NumberValue(12)}, " 12+"},
+		{"##.##^^^^", []Value{NumberValue(12345)}, " 1.23E+04"},
+		{"##.##", []Value{NumberValue(12345.6)}, "%12345.60"},
+		{"## ", []Value{NumberValue(1), NumberValue(22), NumberValue(333)}, " 1 22 %333 "},
+		{"!", []Value{StringValue("HELLO")}, "H"},
+		{"&!", []Value{StringValue("AB"), StringValue("CD")}, "ABC"},
+		{`\  \|`, []Value{StringValue("ABCDEFG")}, "ABCD|"},
+		{`\  \|`, []Value{StringValue("AB")}, "AB  |"},
+		{"TOTAL: ###.##", []Value{NumberValue(7.5)}, "TOTAL:   7.50"},
+	}
+	for _, tt := range tests {
+		got, err := formatUsing(tt.format, tt.vals)
+		if err != nil {
+			t.Errorf("formatUsing(%q, %v): %v", tt.format, tt.vals, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatUsing(%q, %v) = %q, want %q", tt.format, tt.vals, got, tt.want)
+		}
+	}
+}
+
+func TestFormatUsingErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		vals   []Value
+	}{
+		{"###", []Value{StringValue("A")}},
+		{"!", []Value{NumberValue(1)}},
+		{"NO FIELDS", []Value{NumberValue(1)}},
+	}
+	for _, tt := range tests {
+		if got, err := formatUsing(tt.format, tt.vals); err == nil {
+			t.Errorf("formatUsing(%q, %v) = %q, want an error", tt.format, tt.vals, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/**************************************************************/
+/*
+   vm.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+// runVM executes bc.
+func (it *Interpreter) runVM(bc *Bytecode, pc int) error {
+	stack := make([]Value, 0, 16)
+	pop := func() Value {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+
+	cur := pc // index of the running statement
+	ip := bc.Addr[pc]
+	for {
+		in := bc.Code[ip]
+		ip++
+		var err error
+
+		switch in.Op {
+		case OpStmt:
+			if err := it.tick(in.A); err != nil {
+				return err
+			}
+			cur = in.A
+			stack = stack[:0]
+
+		case OpPushNum:
+			stack = append(stack, it.number(bc.Nums[in.A]))
+		case OpPushStr:
+			stack = append(stack, StringValue(bc.Strs[in.A]))
+		case OpLoad:
+			stack = append(stack, it.Env.load(in.A))
+
+		case OpStore:
+			err = it.setSlot(in.A, pop())
+
+		case OpNeg, OpPos:
+			v := pop()
+			if v.Kind != ValNumber {
+				op := "+"
+				if in.Op == OpNeg {
+					op = "-"
+				}
+				err = newError(ErrTypeMismatch, "unary %s requires number", op)
+				break
+			}
+			if in.Op == OpNeg {
+				if v, err = negate(v); err != nil {
+					break
+				}
+			}
+			stack = append(stack, v)
+
+		case OpBinary:
+			top := len(stack) - 1
+			l, r := &stack[top-1], &stack[top]
+			stack = stack[:top]
+			if l.Kind == ValNumber && r.Kind == ValNumber && l.Type == Double && r.Type == Double &&
+				l.Dec == nil && r.Dec == nil && in.A <= binMul {
+				// fast path: the result replaces the left operand
+				switch in.A {
+				case binAdd:
+					l.Num += r.Num
+				case binSub:
+					l.Num -= r.Num
+				case binMul:
+					l.Num *= r.Num
+				}
+				l.lit = l.lit && r.lit
+				break
+			}
+			*l, err = it.binary(binaryOps[in.A], *l, *r)
+
+		case OpCall:
+			args := make([]Value, in.B)
+			copy(args, stack[len(stack)-in.B:])
+			stack = stack[:len(stack)-in.B]
+			var v Value
+			if v, err = it.callFunc(bc.Strs[in.A], args); err == nil {
+				stack = append(stack, v)
+			}
+
+		case OpPrint:
+			err = it.print(it.out, stack[len(stack)-in.A:])
+			stack = stack[:len(stack)-in.A]
+
+		case OpInput:
+			var v Value
+			if v, err = it.input(in.A); err != nil {
+				break
+			}
+			stack = append(stack, v)
+
+		case OpJump:
+			ip = in.A
+
+		case OpJumpFalse:
+			cond := pop()
+			if cond.Kind != ValNumber {
+				err = newError(ErrTypeMismatch, "IF condition must be numeric")
+				break
+			}
+			if it.Coverage != nil {
+				it.Coverage.branch(bc.Lines[cur], in.B, cond.Num != 0)
+			}
+			if cond.Num == 0 {
+				ip = in.A
+			}
+
+		case OpGosub:
+			if err = it.gosub(in.B); err == nil {
+				ip = in.A
+			}
+
+		case OpReturn:
+			var ret int
+			if ret, err = it.ret(); err == nil {
+				ip = bc.Addr[ret]
+			}
+
+		case OpOnError:
+			err = it.setOnError(in.A)
+
+		case OpResume:
+			var target int
+			if target, err = it.resume(bc.Nodes[in.A].(*ResumeStmt), in.B); err == nil {
+				ip = bc.Addr[target]
+			}
+
+		case OpRaise:
+			err = raiseError(pop())
+
+		case OpExec:
+			_, _, err = it.execStmt(bc.Nodes[in.A], nil, cur)
+
+		case OpRandomize:
+			err = it.randomize(pop())
+
+		case OpExt:
+			s := bc.Nodes[in.A].(*ExtStmt)
+			args := make([]Value, in.B)
+			copy(args, stack[len(stack)-in.B:])
+			stack = stack[:len(stack)-in.B]
+			if err = s.ext.exec(it, s, args); err != nil {
+				err = hostError(s.Keyword, err)
+			}
+
+		case OpEnd:
+			return nil
+
+		case OpHalt:
+			return it.finish()
+		}
+
+		if err != nil {
+			pc, err := it.trap(err, cur, bc.Lines[cur], bc.Stmts[cur])
+			if err != nil {
+				return err
+			}
+			ip = bc.Addr[pc]
+		}
+	}
+}
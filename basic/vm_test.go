@@ -0,0 +1,81 @@
+/**************************************************************/
+/*
+   vm_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package basic
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// primesProg counts the primes up to 300 by trial division, taking
+// remainders by repeated subtraction.
+const primesProg = `10 C = 0
+20 N = 2
+30 D = 2
+40 IF D * D > N THEN 100
+50 R = N
+60 IF R < D THEN 80
+70 R = R - D
+75 GOTO 60
+80 IF R = 0 THEN 110
+90 D = D + 1
+95 GOTO 40
+100 C = C + 1
+110 N = N + 1
+120 IF N <= 300 THEN 30
+130 PRINT C
+`
+
+// gosubProg sums 1 to 5000 in a subroutine.
+const gosubProg = `10 S = 0
+20 I = 1
+30 GOSUB 100
+40 I = I + 1
+50 IF I <= 5000 THEN 30
+60 PRINT S
+70 END
+100 S = S + I
+110 RETURN
+`
+
+// parseSource parses a program listing or fails tb.
+func parseSource(tb testing.TB, src string) *Program {
+	tb.Helper()
+	prog, err := ParseProgram(strings.NewReader(src))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return prog
+}
+
+func benchmarkEngine(b *testing.B, engine Engine, src string) {
+	it := NewInterpreter(
+		WithProgram(parseSource(b, src)),
+		WithStdout(io.Discard),
+		WithEngine(engine),
+		WithMaxOps(0),
+	)
+	for b.Loop() {
+		if err := it.Run(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTree(b *testing.B) {
+	b.Run("Primes", func(b *testing.B) { benchmarkEngine(b, EngineTree, primesProg) })
+	b.Run("Gosub", func(b *testing.B) { benchmarkEngine(b, EngineTree, gosubProg) })
+}
+
+func BenchmarkVM(b *testing.B) {
+	b.Run("Primes", func(b *testing.B) { benchmarkEngine(b, EngineVM, primesProg) })
+	b.Run("Gosub", func(b *testing.B) { benchmarkEngine(b, EngineVM, gosubProg) })
+}
@@ -0,0 +1,252 @@
+/**************************************************************/
+/*
+   commands.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kaz399/selfstudy-basic/basic"
+)
+
+const usage = `usage:
+  basic                          start the interactive REPL
+  basic run prog.bas             run a program
+      --profile                  print a per-line profile
+      --pprof file               write the profile in pprof format
+      --cover                    print the listing with coverage counts
+      --lcov file                write coverage in LCOV format
+      --seed n                   seed RND as RANDOMIZE n does
+      --go-format                print numbers in Go's format, e.g. 1e+21
+      --decimal                  use exact decimal arithmetic (OPTION DECIMAL)
+      --scale n                  digits kept by decimal division (default 20)
+      --rounding mode            decimal division rounding: half-even, half-up,
+                                 down, up, floor or ceiling
+  basic build prog.bas -o prog.go  translate a program to Go source
+  basic ast prog.bas             print the parsed program as JSON
+      --decode                   read the JSON and print the program listing
+  basic test [dir|prog.bas ...]  run programs and compare their output with
+                                 prog.out, using prog.in as input
+      --update                   write the actual output to the .out files
+      --parallel n               number of programs run at once
+      --timeout d                time limit of each program (default 10s)
+      -v                         list passing programs too
+A program may also be given as the JSON printed by basic ast, in a file
+ending in .json.`
+
+// command runs a CLI subcommand and returns the exit status.
+func command(name string, args []string) int {
+	switch name {
+	case "run":
+		return runCommand(args)
+	case "build":
+		return buildCommand(args)
+	case "ast":
+		return astCommand(args)
+	case "test":
+		return testCommand(args)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s\n", name, usage)
+		return 2
+	}
+}
+
+func runCommand(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	profile := fs.Bool("profile", false, "print a per-line profile to standard error")
+	pprofFile := fs.String("pprof", "", "write the profile in pprof format to `file`")
+	cover := fs.Bool("cover", false, "print the listing annotated with coverage to standard error")
+	lcovFile := fs.String("lcov", "", "write coverage in LCOV format to `file`")
+	seed := fs.Float64("seed", 0, "RND seed, as set by RANDOMIZE")
+	goFormat := fs.Bool("go-format", false, "print numbers in Go's shortest format instead of the classic BASIC one")
+	decimal := fs.Bool("decimal", false, "use exact decimal arithmetic")
+	scale := fs.Int("scale", basic.DefaultDecimal.Scale, "digits kept after the decimal point by decimal division")
+	rounding := fs.String("rounding", basic.DefaultDecimal.Rounding.String(), "rounding `mode` of decimal division")
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: basic run prog.bas")
+		return 2
+	}
+	prog, err := loadFile(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	mode, err := basic.ParseRounding(*rounding)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *scale < 0 {
+		fmt.Fprintln(os.Stderr, "scale must not be negative")
+		return 2
+	}
+
+	it := basic.NewInterpreter(basic.WithProgram(prog), basic.WithSeed(*seed))
+	if *goFormat {
+		it.NumberFormat = basic.FormatGo
+	}
+	if *decimal {
+		it.Decimal = &basic.DecimalOptions{Scale: *scale, Rounding: mode}
+	}
+	if *profile || *pprofFile != "" {
+		it.Profile = basic.NewProfile()
+		it.Profile.Name = files[0]
+	}
+	if *cover || *lcovFile != "" {
+		it.Coverage = basic.NewCoverage()
+		it.Coverage.Name = files[0]
+	}
+	status := 0
+	if err := it.Run(context.Background()); err != nil {
+		fmt.Println(err)
+		status = 1
+	}
+	if *profile {
+		it.Profile.Report(os.Stderr)
+	}
+	if *pprofFile != "" {
+		if err := writeFile(*pprofFile, it.Profile.WritePprof); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if *cover {
+		it.Coverage.List(os.Stderr, prog)
+	}
+	if *lcovFile != "" {
+		err := writeFile(*lcovFile, func(w io.Writer) error {
+			return it.Coverage.WriteLCOV(w, prog)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// writeFile creates path and fills it with write.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func buildCommand(args []string) int {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	out := fs.String("o", "", "output file (default: standard output)")
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: basic build prog.bas -o prog.go")
+		return 2
+	}
+	prog, err := loadFile(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	src, err := basic.Transpile(prog, files[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", files[0], err)
+		return 1
+	}
+	if *out == "" {
+		fmt.Print(src)
+		return 0
+	}
+	if err := os.WriteFile(*out, []byte(src), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func astCommand(args []string) int {
+	fs := flag.NewFlagSet("ast", flag.ContinueOnError)
+	decode := fs.Bool("decode", false, "read the JSON and print the program listing")
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: basic ast prog.bas")
+		return 2
+	}
+	prog, err := loadFile(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *decode {
+		for _, line := range prog.OrderedLines() {
+			fmt.Printf("%d %s\n", line, prog.Stmts[line])
+		}
+		return 0
+	}
+	if err := basic.WriteAST(os.Stdout, prog); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parseFlags parses args allowing flags after the file arguments, and
+// returns the non-flag arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func loadFile(path string) (*basic.Program, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	read := basic.ParseProgram
+	if strings.HasSuffix(path, ".json") {
+		read = basic.ReadAST
+	}
+	prog, err := read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return prog, nil
+}
@@ -0,0 +1,302 @@
+/**************************************************************/
+/*
+   golden.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaz399/selfstudy-basic/basic"
+)
+
+// goldenOutputLimit bounds the output of a test program.
+const goldenOutputLimit = 1 << 20
+
+// goldenResult is the outcome of running one test program.
+type goldenResult struct {
+	path    string
+	failed  bool
+	updated bool
+	msg     string // diff or error of a failed test
+	elapsed time.Duration
+}
+
+// testCommand runs the programs found in args (default: the current
+// directory) with prog.in as standard input, and compares their output
+// with prog.out.
+func testCommand(args []string) int {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	update := fs.Bool("update", false, "write the actual output to the .out files")
+	parallel := fs.Int("parallel", runtime.GOMAXPROCS(0), "number of programs run at once")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit of each program")
+	verbose := fs.Bool("v", false, "list passing programs too")
+	paths, err := parseFlags(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := findPrograms(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "no .bas files found")
+		return 1
+	}
+
+	results := make([]goldenResult, len(files))
+	sem := make(chan struct{}, max(*parallel, 1))
+	var wg sync.WaitGroup
+	for i, path := range files {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = runGolden(path, *timeout, *update)
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.failed:
+			failed++
+			fmt.Printf("FAIL %s (%.2fs)\n%s", r.path, r.elapsed.Seconds(), r.msg)
+		case r.updated:
+			fmt.Printf("updated %s\n", r.path)
+		case *verbose:
+			fmt.Printf("ok   %s (%.2fs)\n", r.path, r.elapsed.Seconds())
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("FAIL: %d of %d programs failed\n", failed, len(results))
+		return 1
+	}
+	fmt.Printf("ok: %d programs passed\n", len(results))
+	return 0
+}
+
+// findPrograms returns the .bas files given in paths or found below the
+// directories in paths.
+func findPrograms(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && filepath.Ext(path) == ".bas" {
+				files = append(files, path)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// runGolden runs the program at path and checks or, with update, writes
+// its expected output. Files opened by the program are relative to its
+// directory.
+func runGolden(path string, timeout time.Duration, update bool) goldenResult {
+	start := time.Now()
+	r := goldenResult{path: path}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	got, err := runProgram(path, base+".in", timeout)
+	r.elapsed = time.Since(start)
+	if err != nil {
+		r.failed, r.msg = true, err.Error()+"\n"
+		return r
+	}
+
+	want, err := os.ReadFile(base + ".out")
+	switch {
+	case update && (err != nil || !bytes.Equal(got, want)):
+		if err := os.WriteFile(base+".out", got, 0o644); err != nil {
+			r.failed, r.msg = true, err.Error()+"\n"
+			return r
+		}
+		r.updated = true
+	case errors.Is(err, fs.ErrNotExist):
+		r.failed, r.msg = true, fmt.Sprintf("missing %s.out (run with -update to create it)\n", base)
+	case err != nil:
+		r.failed, r.msg = true, err.Error()+"\n"
+	case !bytes.Equal(got, want):
+		r.failed = true
+		r.msg = unifiedDiff(base+".out", "output", string(want), string(got))
+	}
+	return r
+}
+
+// runProgram runs the program at path with the file in as standard input,
+// if it exists, and returns what it printed. A runtime error is part of
+// the output, as basic run prints it, but running out of time or output
+// is an error: the program never finished. The time limit replaces the
+// operation limit.
+func runProgram(path, in string, timeout time.Duration) ([]byte, error) {
+	prog, err := loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := os.ReadFile(in)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var out bytes.Buffer
+	it := basic.NewInterpreter(
+		basic.WithProgram(prog),
+		basic.WithStdin(bytes.NewReader(stdin)),
+		basic.WithStdout(&out),
+		basic.WithFS(basic.DirFS(filepath.Dir(path))),
+		basic.WithLimits(basic.Limits{MaxDuration: timeout, MaxOutputBytes: goldenOutputLimit}),
+		basic.WithMaxOps(0),
+	)
+	if err := it.Run(context.Background()); err != nil {
+		var tl *basic.TimeLimitError
+		var ol *basic.OutputLimitError
+		if errors.As(err, &tl) || errors.As(err, &ol) {
+			return nil, err
+		}
+		fmt.Fprintln(&out, err)
+	}
+	return out.Bytes(), nil
+}
+
+// maxDiffCells bounds the table used to compare two outputs, about a
+// thousand lines each.
+const maxDiffCells = 1 << 20
+
+// unifiedDiff returns the lines changed from a to b in unified format,
+// with three lines of context.
+func unifiedDiff(aName, bName, a, b string) string {
+	const ctxLines = 3
+	al, bl := splitLines(a), splitLines(b)
+	if (len(al)+1)*(len(bl)+1) > maxDiffCells {
+		return fmt.Sprintf("%s and %s differ (too long to compare)\n", aName, bName)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of al[i:]
+	// and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	type edit struct {
+		op   byte // ' ', '-' or '+'
+		text string
+		i, j int // lines of a and b before this one
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			edits = append(edits, edit{' ', al[i], i, j})
+			i, j = i+1, j+1
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', al[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', bl[j], i, j})
+			j++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+	for k := 0; k < len(edits); {
+		if edits[k].op == ' ' {
+			k++
+			continue
+		}
+		// a hunk runs from ctxLines lines before the change to ctxLines
+		// lines after the last change at most 2*ctxLines unchanged lines
+		// apart, as in diff -u
+		start := max(k-ctxLines, 0)
+		end := k
+		for n := k; n < len(edits) && n-end <= 2*ctxLines+1; n++ {
+			if edits[n].op != ' ' {
+				end = n
+			}
+		}
+		end = min(end+ctxLines+1, len(edits))
+		var ac, bc int
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				ac++
+			}
+			if e.op != '-' {
+				bc++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(edits[start].i, ac), hunkRange(edits[start].j, bc))
+		for _, e := range edits[start:end] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.text)
+			if !strings.HasSuffix(e.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats the start and length of a hunk; an empty range
+// starts at the line before it.
+func hunkRange(before, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if n == 1 {
+		return fmt.Sprint(before + 1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+// splitLines splits s after each newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,178 @@
+/**************************************************************/
+/*
+   golden_test.go
+
+   Copyright 2026 Yabe.Kazuhiro
+*/
+/**************************************************************/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sixteen is the lines 1 to 16 that the diff tests change.
+const sixteen = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"Change", sixteen, strings.Replace(sixteen, "\n8\n", "\nX\n", 1), `--- want
++++ got
+@@ -5,7 +5,7 @@
+ 5
+ 6
+ 7
+-8
++X
+ 9
+ 10
+ 11
+`},
+		{"Merged", sixteen, strings.NewReplacer("\n2\n", "\nX\n", "\n9\n", "\nY\n").Replace(sixteen), `--- want
++++ got
+@@ -1,12 +1,12 @@
+ 1
+-2
++X
+ 3
+ 4
+ 5
+ 6
+ 7
+ 8
+-9
++Y
+ 10
+ 11
+ 12
+`},
+		{"Apart", sixteen, strings.NewReplacer("\n2\n", "\nX\n", "\n10\n", "\nY\n").Replace(sixteen), `--- want
++++ got
+@@ -1,5 +1,5 @@
+ 1
+-2
++X
+ 3
+ 4
+ 5
+@@ -7,7 +7,7 @@
+ 7
+ 8
+ 9
+-10
++Y
+ 11
+ 12
+ 13
+`},
+		{"Insert", sixteen, strings.Replace(sixteen, "\n8\n", "\n8\nX\nY\n", 1), `--- want
++++ got
+@@ -6,6 +6,8 @@
+ 6
+ 7
+ 8
++X
++Y
+ 9
+ 10
+ 11
+`},
+		{"InsertFirst", "", "X\n", `--- want
++++ got
+@@ -0,0 +1 @@
++X
+`},
+		{"Delete", sixteen, strings.Replace(sixteen, "\n8\n9\n", "\n", 1), `--- want
++++ got
+@@ -5,8 +5,6 @@
+ 5
+ 6
+ 7
+-8
+-9
+ 10
+ 11
+ 12
+`},
+		{"DeleteAll", "A\nB\n", "", `--- want
++++ got
+@@ -1,2 +0,0 @@
+-A
+-B
+`},
+		{"NoNewline", "A\nB", "A\nC", `--- want
++++ got
+@@ -1,2 +1,2 @@
+ A
+-B
+\ No newline at end of file
++C
+\ No newline at end of file
+`},
+		{"NewlineAdded", "A\nB", "A\nB\n", `--- want
++++ got
+@@ -1,2 +1,2 @@
+ A
+-B
+\ No newline at end of file
++B
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("want", "got", tt.a, tt.b); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		before, n int
+		want      string
+	}{
+		{0, 0, "0,0"},
+		{4, 0, "4,0"},
+		{0, 1, "1"},
+		{4, 1, "5"},
+		{4, 3, "5,3"},
+	}
+	for _, tt := range tests {
+		if got := hunkRange(tt.before, tt.n); got != tt.want {
+			t.Errorf("hunkRange(%d, %d) = %q, want %q", tt.before, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRunProgramLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"Time", "10 GOTO 10\n", "time limit exceeded"},
+		{"Output", "10 PRINT \"OUTPUT\"\n20 GOTO 10\n", "output limit exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prog.bas")
+			if err := os.WriteFile(path, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			out, err := runProgram(path, "prog.in", 100*time.Millisecond)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || out != nil {
+				t.Errorf("runProgram = %q, %v, want error %q", out, err, tt.want)
+			}
+		})
+	}
+}
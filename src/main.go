@@ -10,20 +10,30 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
+
+	"github.com/kaz399/selfstudy-basic/basic"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(command(os.Args[1], os.Args[2:]))
+	}
+	repl()
+}
+
+func repl() {
 	reader := bufio.NewReader(os.Stdin)
-	prog := NewProgram()
+	prog := basic.NewProgram()
+	engine := basic.EngineTree
 
 	fmt.Println("MINI BASIC v0.1 (Go study scaffold")
-	fmt.Println("Commands: RUN, LIST, NEW")
+	fmt.Println("Commands: RUN [PROFILE|COVERAGE], LIST, NEW, ENGINE [TREE|VM|CLOSURE], OPTIMIZE [ON|OFF]")
 	fmt.Println("Enter line-numbered statements, e.g. `10 PRINT \"HELLO\"`")
 
 	for {
@@ -45,25 +55,10 @@ func main() {
 			continue
 		}
 
-		if lineNo, rest, ok := splitLeadingLineNumber(line); ok {
-			rest = strings.TrimSpace(rest)
-
-			if rest == "" {
-				prog.DeleteLine(lineNo)
-				if errors.Is(err, io.EOF) {
-					return
-				}
-				continue
-			}
-			stmt, parseErrs := parseOneStatement(rest)
-			if len(parseErrs) > 0 {
-				fmt.Printf("Syntax error at line %d: %s\n", lineNo, strings.Join(parseErrs, "; "))
-				if errors.Is(err, io.EOF) {
-					return
-				}
-				continue
+		if lineNo, rest, ok := basic.SplitLineNumber(line); ok {
+			if perr := prog.EnterLine(lineNo, rest); perr != nil {
+				fmt.Println(perr)
 			}
-			prog.SetLine(lineNo, rest, stmt)
 			if errors.Is(err, io.EOF) {
 				return
 			}
@@ -72,18 +67,54 @@ func main() {
 
 		cmd := strings.ToUpper(strings.TrimSpace(line))
 		switch cmd {
-		case "RUN":
-			it := NewInterpreter(prog, reader, os.Stdout)
-			it.ResetEnv()
-			if err := it.Run(); err != nil {
+		case "RUN", "RUN PROFILE", "RUN COVERAGE":
+			it := basic.NewInterpreter(basic.WithProgram(prog), basic.WithStdin(reader), basic.WithEngine(engine))
+			switch cmd {
+			case "RUN PROFILE":
+				it.Profile = basic.NewProfile()
+			case "RUN COVERAGE":
+				it.Coverage = basic.NewCoverage()
+			}
+			if err := it.Run(context.Background()); err != nil {
 				fmt.Println(err)
 			}
+			if it.Profile != nil {
+				it.Profile.Report(os.Stdout)
+			}
+			if it.Coverage != nil {
+				it.Coverage.List(os.Stdout, prog)
+			}
 		case "LIST":
 			for _, ln := range prog.OrderedLines() {
 				fmt.Printf("%d %s\n", ln, prog.Source[ln])
 			}
 		case "NEW":
 			prog.Clear()
+		case "ENGINE":
+			switch engine {
+			case basic.EngineVM:
+				fmt.Println("VM")
+			case basic.EngineClosure:
+				fmt.Println("CLOSURE")
+			default:
+				fmt.Println("TREE")
+			}
+		case "ENGINE TREE":
+			engine = basic.EngineTree
+		case "ENGINE VM":
+			engine = basic.EngineVM
+		case "ENGINE CLOSURE":
+			engine = basic.EngineClosure
+		case "OPTIMIZE":
+			if prog.Optimized() {
+				fmt.Println("ON")
+			} else {
+				fmt.Println("OFF")
+			}
+		case "OPTIMIZE ON":
+			prog.SetOptimize(true)
+		case "OPTIMIZE OFF":
+			prog.SetOptimize(false)
 		default:
 			fmt.Println("Unknown command (use RUN/LIST/NEW or line-numbered statement)")
 		}
@@ -93,55 +124,3 @@ func main() {
 		}
 	}
 }
-
-func parseOneStatement(src string) (Stmt, []string) {
-	p := NewParser(src)
-	stmt := p.ParseStatement()
-	if stmt == nil {
-		return nil, p.Errors()
-	}
-
-	if p.peekTok.Type != EOF {
-		if p.curTok.Type != EOF {
-
-		}
-	}
-	if len(p.Errors()) > 0 {
-		return nil, p.Errors()
-	}
-	return stmt, nil
-}
-
-func splitLeadingLineNumber(s string) (lineNo int, rest string, ok bool) {
-	i := 0
-	for i < len(s) && s[i] == ' ' {
-		i++
-	}
-	start := i
-	for i < len(s) && isDigit(s[i]) {
-		i++
-	}
-	if i == start {
-		return 0, "", false
-	}
-
-	if i < len(s) && s[i] != ' ' && s[i] != '\t' {
-		return 0, "", false
-	}
-	n, err := parseIntStrict(s[start:i])
-	if err != nil || n <= 0 {
-		return 0, "", false
-	}
-	return n, s[i:], true
-}
-
-func parseIntStrict(s string) (int, error) {
-	if strings.Contains(s, ",") {
-		return 0, fmt.Errorf("must be integer")
-	}
-	n, err := strconv.Atoi(s)
-	if err != nil {
-		return 0, err
-	}
-	return n, nil
-}